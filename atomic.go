@@ -0,0 +1,102 @@
+package oneid
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// AtomicGenerator is a lock-free alternative to Generator for uint64 ids,
+// it packs LastTime and Sequence into a single word updated by compare-and-swap,
+// so concurrent callers never wait on the mutex of the config.
+//
+// It keeps its own LastTime and Sequence seeded from the config at construction,
+// ids of the same serverID and processID must not also be generated through the config afterwards.
+// Its ids are strictly increasing like the ones of Generator.
+type AtomicGenerator struct {
+	// state is LastTime<<SequenceBits | Sequence, it is the first field
+	// so it stays 64-bit aligned for sync/atomic on 32-bit platforms.
+	state uint64
+
+	// clockHigh is the highest clock reading like the one of the config, it follows state to stay aligned.
+	clockHigh uint64
+
+	config *Uint64Config
+	base   uint64
+}
+
+// NewAtomicGenerator binds serverID and processID to c like NewGenerator.
+func NewAtomicGenerator(c *Uint64Config, serverID, processID uint64) (*AtomicGenerator, error) {
+	if err := c.checkIDs(serverID, processID); err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	state := c.LastTime<<c.SequenceBits | c.Sequence&mask(c.SequenceBits)
+	clockHigh := c.clockHigh
+	c.Unlock()
+
+	return &AtomicGenerator{
+		state:     state,
+		clockHigh: clockHigh,
+		config:    c,
+		base:      c.base(serverID, processID),
+	}, nil
+}
+
+// Next generates an id, it returns the same errors as Config.Next.
+func (g *AtomicGenerator) Next() (uint64, error) {
+	c := g.config
+	seqMask := mask(c.SequenceBits)
+
+	for {
+		old := atomic.LoadUint64(&g.state)
+		last, seq := old>>c.SequenceBits, old&seqMask
+
+		now := c.now()
+		if now > c.timestampBitsMask() {
+			return 0, fmt.Errorf("%w: the timestamp bits expired at %s", ErrTimestampOverflow, c.Expires())
+		}
+
+		high := atomic.LoadUint64(&g.clockHigh)
+		if now < high {
+			switch c.RollbackPolicy {
+			case RollbackBlock:
+				_ = c.sleep(context.Background(), c.until(c.tickTime(high)))
+
+				continue
+			case RollbackError:
+				return 0, fmt.Errorf("%w: clock reads %s behind its latest reading",
+					ErrClockMovedBackwards, time.Duration(high-now)*c.tick())
+			}
+		}
+
+		for now > high && !atomic.CompareAndSwapUint64(&g.clockHigh, high, now) {
+			high = atomic.LoadUint64(&g.clockHigh)
+		}
+
+		next := now << c.SequenceBits
+
+		if now <= last {
+			if seq == seqMask {
+				return 0, fmt.Errorf("%w: %d ids issued in tick %d", ErrSequenceExhausted, seq+1, last)
+			}
+
+			next = old + 1
+		}
+
+		if atomic.CompareAndSwapUint64(&g.state, old, next) {
+			if err := c.persist(next >> c.SequenceBits); err != nil {
+				return 0, err
+			}
+
+			return c.packBase(next>>c.SequenceBits, g.base, next&seqMask), nil
+		}
+	}
+}
+
+// Config returns the config g generates ids from, e.g. to Decode them.
+func (g *AtomicGenerator) Config() *Uint64Config {
+	return g.config
+}
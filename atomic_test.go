@@ -0,0 +1,147 @@
+package oneid
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coderme/oneid/v3/oneidtest"
+)
+
+var _ IDGenerator[uint64] = (*AtomicGenerator)(nil)
+
+// TestAtomicGeneratorNext tests AtomicGenerator never repeats an id across goroutines.
+func TestAtomicGeneratorNext(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 17)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := NewAtomicGenerator(&c, 1024, 1); !errors.Is(err, ErrServerIDOutOfRange) {
+		t.Error("expected ErrServerIDOutOfRange, found:", err)
+	}
+
+	g, err := NewAtomicGenerator(&c, 700, 21)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	var wg sync.WaitGroup
+
+	ids := newIDChecker(t, &c, 700, 21)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 10000; i++ {
+				id, err := g.Next()
+				if err != nil {
+					t.Error("unexpected error:", err)
+
+					return
+				}
+
+				if !ids.check(id) {
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// TestAtomicGeneratorErrors tests AtomicGenerator reports an exhausted sequence and a clock moved backwards.
+func TestAtomicGeneratorErrors(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+	clk := oneidtest.NewClock(start)
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 4, WithRollbackPolicy(RollbackError), WithClock(clk))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewAtomicGenerator(&c, 1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := g.Next(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	clk.Set(start.Add(-time.Minute))
+
+	if _, err := g.Next(); !errors.Is(err, ErrClockMovedBackwards) {
+		t.Error("expected ErrClockMovedBackwards, found:", err)
+	}
+
+	c.RollbackPolicy = RollbackBorrow
+
+	for i := 1; i < 1<<c.SequenceBits; i++ {
+		if _, err := g.Next(); err != nil {
+			t.Fatal("unexpected error at sequence", i, "error:", err)
+		}
+	}
+
+	if _, err := g.Next(); !errors.Is(err, ErrSequenceExhausted) {
+		t.Error("expected ErrSequenceExhausted, found:", err)
+	}
+}
+
+// TestAtomicGeneratorMonotonic tests the ids of AtomicGenerator are strictly increasing across ticks under contention.
+func TestAtomicGeneratorMonotonic(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 4, WithTick(time.Millisecond))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewAtomicGenerator(&c, 1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testMonotonic(t, g.Next)
+}
+
+// BenchmarkGeneratorParallel benchmarks the mutex-guarded Generator under contention.
+func BenchmarkGeneratorParallel(b *testing.B) {
+	c := mustUint64Config(NewUint64ConfigWithOptions(1, 1, 30))
+
+	g, err := NewGenerator(&c, 1, 1)
+	if err != nil {
+		b.Fatal("unexpected error:", err)
+	}
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = g.Next()
+		}
+	})
+}
+
+// BenchmarkAtomicGeneratorParallel benchmarks AtomicGenerator under contention.
+func BenchmarkAtomicGeneratorParallel(b *testing.B) {
+	c := mustUint64Config(NewUint64ConfigWithOptions(1, 1, 30))
+
+	g, err := NewAtomicGenerator(&c, 1, 1)
+	if err != nil {
+		b.Fatal("unexpected error:", err)
+	}
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = g.Next()
+		}
+	})
+}
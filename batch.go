@@ -0,0 +1,178 @@
+package oneid
+
+import (
+	"context"
+	"fmt"
+)
+
+// Range is a run of ids reserved at once by Generator.Reserve,
+// it holds n consecutive sequence numbers starting at a timestamp rather than the ids themselves.
+//
+// The run continues into the following ticks when it outgrows the sequence of its first tick,
+// so the ids are unique and ascending though not necessarily adjacent integers.
+type Range[T ID] struct {
+	config *Config[T]
+	base   T
+
+	// start is the timestamp of the first id followed by its sequence, n is the number of ids.
+	start uint64
+	n     int
+}
+
+// Len returns the number of ids in r.
+func (r Range[T]) Len() int {
+	return r.n
+}
+
+// At returns the i-th id of r, it panics if i is out of range like indexing a slice.
+func (r Range[T]) At(i int) T {
+	if i < 0 || i >= r.n {
+		panic(fmt.Sprintf("oneid: index %d out of range [0:%d]", i, r.n))
+	}
+
+	pos := r.start + uint64(i)
+
+	return r.config.packBase(T(pos>>r.config.SequenceBits), r.base, T(pos)&mask(r.config.SequenceBits))
+}
+
+// IDs returns the ids of r as a slice.
+func (r Range[T]) IDs() []T {
+	ids := make([]T, r.n)
+	for i := range ids {
+		ids[i] = r.At(i)
+	}
+
+	return ids
+}
+
+// Reserve reserves n ids in a single call, it takes the lock of the config once
+// and borrows the following ticks when the sequence of the current tick is not enough.
+//
+// ErrTimestampOverflow is returned when the run would outlive the timestamp bits
+// and ErrDriftExceeded when it would borrow ticks further ahead than MaxDrift.
+func (g *Generator[T]) Reserve(n int) (Range[T], error) {
+	return g.reserve(context.Background(), n, false)
+}
+
+// reserve reserves n ids under the lock of the config,
+// it waits for the clock to catch up instead of returning ErrDriftExceeded when wait is set.
+func (g *Generator[T]) reserve(ctx context.Context, n int, wait bool) (Range[T], error) {
+	if n < 1 {
+		return Range[T]{}, fmt.Errorf("cannot reserve %d ids", n)
+	}
+
+	c := g.config
+
+	c.Lock()
+	defer c.Unlock()
+
+	for {
+		if err := c.readClock(ctx); err != nil {
+			return Range[T]{}, err
+		}
+
+		start := c.nextPosition()
+
+		limit := uint64(c.timestampBitsMask())<<c.SequenceBits | uint64(mask(c.SequenceBits))
+		if start > limit || uint64(n-1) > limit-start {
+			return Range[T]{}, fmt.Errorf("%w: %d ids do not fit before %s",
+				ErrTimestampOverflow, n, c.Expires())
+		}
+
+		end := start + uint64(n-1)
+
+		if d := c.driftWait(T(end >> c.SequenceBits)); d > 0 {
+			if !wait {
+				return Range[T]{}, fmt.Errorf("%w: %d ids would run %s ahead of the clock, %s at most",
+					ErrDriftExceeded, n, d+c.MaxDrift, c.MaxDrift)
+			}
+
+			if err := c.sleepUnlocked(ctx, d); err != nil {
+				return Range[T]{}, err
+			}
+
+			continue
+		}
+
+		c.LastTime = T(end >> c.SequenceBits)
+		c.Sequence = T(end) & mask(c.SequenceBits)
+
+		if err := c.persist(c.LastTime); err != nil {
+			return Range[T]{}, err
+		}
+
+		return Range[T]{
+			config: c,
+			base:   g.base,
+			start:  start,
+			n:      n,
+		}, nil
+	}
+}
+
+// reserveTick reserves up to n ids left in the tick of the latest id or the clock under the lock of the config,
+// waiting for the next tick while the sequence of the current one is exhausted like NextContext,
+// so unlike reserve it never borrows the following ticks.
+func (g *Generator[T]) reserveTick(ctx context.Context, n int) (Range[T], error) {
+	c := g.config
+
+	c.Lock()
+	defer c.Unlock()
+
+	for {
+		if err := c.readClock(ctx); err != nil {
+			return Range[T]{}, err
+		}
+
+		start := c.nextPosition()
+
+		limit := uint64(c.timestampBitsMask())<<c.SequenceBits | uint64(mask(c.SequenceBits))
+		if start > limit {
+			return Range[T]{}, fmt.Errorf("%w: no ids left before %s", ErrTimestampOverflow, c.Expires())
+		}
+
+		if tick := T(start >> c.SequenceBits); tick > c.CustomEpoch && tick > c.LastTime {
+			if err := c.sleepUnlocked(ctx, c.until(c.tickTime(tick))); err != nil {
+				return Range[T]{}, err
+			}
+
+			continue
+		}
+
+		end := min(start+uint64(n-1), start|uint64(mask(c.SequenceBits)))
+
+		c.LastTime = T(end >> c.SequenceBits)
+		c.Sequence = T(end) & mask(c.SequenceBits)
+
+		if err := c.persist(c.LastTime); err != nil {
+			return Range[T]{}, err
+		}
+
+		return Range[T]{
+			config: c,
+			base:   g.base,
+			start:  start,
+			n:      int(end-start) + 1,
+		}, nil
+	}
+}
+
+// nextPosition returns the position of the id following the latest one under c's lock after readClock,
+// positions count the ids of every tick in turn: timestamp<<SequenceBits | sequence.
+func (c *Config[T]) nextPosition() uint64 {
+	if c.CustomEpoch > c.LastTime {
+		return uint64(c.CustomEpoch) << c.SequenceBits
+	}
+
+	return (uint64(c.LastTime)<<c.SequenceBits | uint64(c.Sequence)) + 1
+}
+
+// NextN generates n ids in a single call, it returns the same errors as Reserve.
+func (g *Generator[T]) NextN(n int) ([]T, error) {
+	r, err := g.Reserve(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.IDs(), nil
+}
@@ -0,0 +1,152 @@
+package oneid
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestGeneratorReserve tests Reserve spills into the following ticks without repeating ids.
+func TestGeneratorReserve(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 4, WithEpoch(time.Now().Add(-time.Hour)))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewGenerator(&c, 700, 21)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := g.Reserve(0); err == nil {
+		t.Error("expected error reserving no ids, found none")
+	}
+
+	first, err := g.Next()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	firstTick := c.LastTime
+
+	// 100 ids need more than 6 ticks of 16 sequence numbers.
+	r, err := g.Reserve(100)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if r.Len() != 100 {
+		t.Fatal("expected 100 ids, found:", r.Len())
+	}
+
+	prev := first
+	ids := newIDChecker(t, &c, 700, 21)
+
+	for i, id := range r.IDs() {
+		if id <= prev {
+			t.Fatal("id", i, id, "is not after", prev)
+		}
+
+		if id != r.At(i) {
+			t.Fatal("IDs and At disagree at", i)
+		}
+
+		if !ids.check(id) {
+			t.FailNow()
+		}
+
+		prev = id
+	}
+
+	if c.LastTime < firstTick+6 {
+		t.Error("expected the reservation to borrow at least 6 ticks, latest tick:", c.LastTime)
+	}
+
+	// the generator carries on after the reserved ids.
+	next, err := g.Next()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if next <= prev {
+		t.Error("id", next, "is not after the reserved", prev)
+	}
+}
+
+// TestGeneratorNextN tests NextN on an uint32 layout and reports a run outliving the timestamp bits.
+func TestGeneratorNextN(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint32ConfigWithOptions(4, 2, 10)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewGenerator(&c, 5, 3)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ids, err := g.NextN(3000)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	seen := map[uint32]struct{}{}
+
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			t.Fatal("duplicate id:", id)
+		}
+
+		seen[id] = struct{}{}
+	}
+
+	if _, err := g.NextN(1 << 27); !errors.Is(err, ErrTimestampOverflow) {
+		t.Error("expected ErrTimestampOverflow, found:", err)
+	}
+}
+
+// TestGeneratorReserveMaxDrift tests Reserve refuses to borrow ticks beyond MaxDrift.
+func TestGeneratorReserveMaxDrift(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 4, WithMaxDrift(3*time.Second))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewGenerator(&c, 1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// 16 ids of the current tick and 16 of each of the 3 borrowed ones.
+	if _, err := g.Reserve(4 * 16); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if d := c.Drift(); d == 0 || d > 3*time.Second {
+		t.Error("expected a drift of up to 3s, found:", d)
+	}
+
+	if _, err := g.NextN(16); !errors.Is(err, ErrDriftExceeded) {
+		t.Error("expected ErrDriftExceeded, found:", err)
+	}
+}
+
+// BenchmarkGeneratorNextN benchmarks NextN(1000) per id.
+func BenchmarkGeneratorNextN(b *testing.B) {
+	c := mustUint64Config(NewUint64ConfigWithOptions(1, 1, 30))
+
+	g, err := NewGenerator(&c, 1, 1)
+	if err != nil {
+		b.Fatal("unexpected error:", err)
+	}
+
+	for i := 0; i < b.N; i += 1000 {
+		_, _ = g.NextN(1000)
+	}
+}
@@ -0,0 +1,29 @@
+package oneid
+
+import "time"
+
+// Clock tells the time and waits for it to pass, configs read it instead of time.Now
+// so tests can control time, see the oneidtest package.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After returns a channel receiving the time once d has passed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the Clock of the system and the default of configs.
+//
+// The times it returns carry a monotonic reading, so waits and the durations between them
+// are immune to wall-clock jumps, while ticks are still counted on the wall clock.
+type SystemClock struct{}
+
+// Now returns time.Now().
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// After returns time.After(d).
+func (SystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -0,0 +1,595 @@
+package oneid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// ID is the set of integer types ids are generated as.
+type ID interface {
+	uint32 | uint64
+}
+
+// Config is cocurrently-safe stateful configuration
+// for raceless id generatation, Uint32Config and Uint64Config are its instances.
+//
+// Epoch is the unix time in seconds the timestamp bits are relative to,
+// CustomEpoch holds the clock reading (ticks since Epoch) seen by the latest call,
+// LastTime and Sequence hold the timestamp and the sequence of the latest id.
+//
+// The timestamp bits count TickDuration, a second for uint64 and an hour for uint32 by default.
+//
+// Ids of the same serverID and processID are strictly increasing in the order they are issued,
+// whatever the clock does: across ticks, borrowed ticks and concurrent callers.
+// Errors are returned rather than breaking the order, e.g. ErrTimestampOverflow instead of wrapping around.
+type Config[T ID] struct {
+	Epoch,
+	CustomEpoch,
+	LastTime,
+	Sequence,
+	ProcessBits,
+	ServerBits,
+	SequenceBits T
+
+	// TickDuration is the resolution of the timestamp, zero means the default of T.
+	TickDuration time.Duration
+
+	// RollbackPolicy is applied when the clock reads behind a time it has already read.
+	RollbackPolicy RollbackPolicy
+
+	// MaxDrift bounds how far ids may borrow the following ticks ahead of the clock,
+	// zero means unbounded. See WithMaxDrift.
+	MaxDrift time.Duration
+
+	// LaneBits are the top bits of the sequence reserved for the lanes of a ShardedGenerator.
+	LaneBits T
+
+	// Clock is read for the current time and waited on, nil means SystemClock. See WithClock.
+	Clock Clock
+
+	// Layout orders the segments of ids, it is set by NewConfig.
+	// Without it ids are made of the timestamp, ServerBits, ProcessBits and SequenceBits in this order.
+	Layout *Layout
+
+	// fixed holds the values set by WithSegmentValue in their segments.
+	fixed T
+
+	// types holds the type names registered by WithType.
+	types typeRegistry[T]
+
+	// state persists the high-water mark of the ids, see WithStateFile.
+	state *stateFile
+
+	// laneMask is the largest sequence of a lane of a ShardedGenerator, which counts the ids
+	// below the lane in the sequence, zero means the whole sequence is counted.
+	laneMask T
+
+	// clockHigh is the highest clock reading, the clock reading behind it moved backwards
+	// while LastTime may only be ahead of it because ids borrowed the following ticks.
+	clockHigh T
+
+	*sync.Mutex
+}
+
+// NewConfig makes Config of T, uint32 or uint64, from the segments of layout
+// and validates it like NewUint64ConfigWithOptions.
+//
+// The bits of the server, process and sequence segments are set as ServerBits, ProcessBits
+// and SequenceBits, the server and process segments may be left out for serverID and processID of zero.
+// Use WithDatacenterID for the datacenter segment, WithType and NewTypedGenerator for the type segment
+// and WithSegmentValue for the other ones.
+func NewConfig[T ID](layout *Layout, opts ...Option) (Config[T], error) {
+	if layout == nil {
+		return Config[T]{}, fmt.Errorf("%w: layout cannot be nil", ErrInvalidLayout)
+	}
+
+	bits := func(k SegmentKind) T {
+		s, _, _ := layout.kind(k)
+
+		return T(s.Bits)
+	}
+
+	return newConfig(bits(SegmentServer), bits(SegmentProcess), bits(SegmentSequence), layout, opts)
+}
+
+// newConfig makes Config from the exact arguments provided and validates it,
+// it backs NewConfig, NewUint32ConfigWithOptions and NewUint64ConfigWithOptions.
+func newConfig[T ID](serverBits, processBits, sequenceBits T, layout *Layout, opts []Option) (Config[T], error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return Config[T]{}, err
+	}
+
+	if uint64(o.epoch.Unix()) > uint64(^T(0)) {
+		return Config[T]{}, fmt.Errorf("%w: %s does not fit in uint%d seconds", ErrInvalidEpoch, o.epoch, width[T]())
+	}
+
+	if o.lanes >= uint(width[T]()) {
+		return Config[T]{}, fmt.Errorf("%w: %d lane bits exceed %d bits", ErrInvalidLayout, o.lanes, width[T]())
+	}
+
+	c := Config[T]{
+		Epoch:          T(o.epoch.Unix()),
+		ProcessBits:    processBits,
+		ServerBits:     serverBits,
+		SequenceBits:   sequenceBits,
+		TickDuration:   o.tickOr(defaultTick[T]()),
+		RollbackPolicy: o.rollback,
+		MaxDrift:       o.maxDrift,
+		LaneBits:       T(o.lanes),
+		Clock:          o.clock,
+		Layout:         layout,
+		Mutex:          &sync.Mutex{},
+	}
+
+	if err := c.Validate(); err != nil {
+		return Config[T]{}, err
+	}
+
+	if err := c.setValues(o.values); err != nil {
+		return Config[T]{}, err
+	}
+
+	if o.datacenterSet {
+		if err := c.setDatacenterID(o.datacenterID); err != nil {
+			return Config[T]{}, err
+		}
+	}
+
+	if err := c.setTypes(o.types); err != nil {
+		return Config[T]{}, err
+	}
+
+	if o.stateFile != "" {
+		if c.state, err = openStateFile(o.stateFile, o.persistIntervalOr(defaultPersistInterval)); err != nil {
+			return Config[T]{}, err
+		}
+
+		c.restoreState()
+	}
+
+	if o.startupGuard {
+		c.waitStartup(o.guardWindow)
+	}
+
+	return c, nil
+}
+
+// Validate reports whether c has a usable layout:
+// each of ServerBits, ProcessBits and SequenceBits must be at least one bit,
+// and at least 16 bits out of 32 or 32 bits out of 64 must be left for the timestamp.
+// With a Layout, its segments must fit in T instead and agree with the bits of c.
+// LaneBits must leave at least one bit of the sequence to each lane.
+// It also rejects an Epoch in the future and a layout whose Lifetime is already over.
+func (c *Config[T]) Validate() error {
+	validateBits := c.validateBits
+	if c.Layout != nil {
+		validateBits = c.validateLayout
+	}
+
+	if err := validateBits(); err != nil {
+		return err
+	}
+
+	if c.LaneBits >= c.SequenceBits {
+		return fmt.Errorf("%w: laneBits(%d) must be less than sequenceBits(%d)",
+			ErrInvalidLayout, c.LaneBits, c.SequenceBits)
+	}
+
+	if uint64(c.Epoch) > uint64(c.clock().Now().Unix()) {
+		return fmt.Errorf("%w: %d", ErrEpochInFuture, c.Epoch)
+	}
+
+	if expires := c.Expires(); !expires.After(c.clock().Now()) {
+		return fmt.Errorf("%w: %d bits of %s for the timestamp expired at %s",
+			ErrInvalidLayout, c.timestampBits(), c.tick(), expires)
+	}
+
+	return nil
+}
+
+// validateBits reports whether ServerBits, ProcessBits and SequenceBits of c leave enough bits for the timestamp.
+func (c *Config[T]) validateBits() error {
+	switch {
+	case c.ServerBits == 0:
+		return fmt.Errorf("%w: serverBits cannot be zero", ErrInvalidLayout)
+	case c.ProcessBits == 0:
+		return fmt.Errorf("%w: processBits cannot be zero", ErrInvalidLayout)
+	case c.SequenceBits == 0:
+		return fmt.Errorf("%w: sequenceBits cannot be zero", ErrInvalidLayout)
+	}
+
+	// checking each of the bits guards the sum against overflow.
+	n := width[T]()
+	used := c.ServerBits + c.ProcessBits + c.SequenceBits
+
+	if c.ServerBits > n || c.ProcessBits > n || c.SequenceBits > n || used > n {
+		return fmt.Errorf("%w: serverBits(%d) + processBits(%d) + sequenceBits(%d) exceed %d bits",
+			ErrInvalidLayout, c.ServerBits, c.ProcessBits, c.SequenceBits, n)
+	}
+
+	if n-used < minTimestampBits[T]() {
+		return fmt.Errorf("%w: %d bits left for the timestamp, at least %d are needed",
+			ErrInvalidLayout, n-used, minTimestampBits[T]())
+	}
+
+	return nil
+}
+
+// Lifetime returns how long the timestamp bits of c last from Epoch
+// before they wrap around and ids are no longer unique.
+func (c *Config[T]) Lifetime() time.Duration {
+	ticks := c.timestampBitsMask()
+	if uint64(ticks) >= uint64(math.MaxInt64/c.tick()) {
+		return math.MaxInt64
+	}
+
+	return (time.Duration(ticks) + 1) * c.tick()
+}
+
+// Expires returns the time the timestamp bits of c wrap around, that is Epoch plus Lifetime.
+func (c *Config[T]) Expires() time.Time {
+	return c.epoch().Add(c.Lifetime())
+}
+
+// timestampBitsMask returns the largest timestamp the layout of c can hold.
+func (c *Config[T]) timestampBitsMask() T {
+	return mask(c.timestampBits())
+}
+
+// timestampBits returns the number of bits the layout of c leaves for the timestamp.
+func (c *Config[T]) timestampBits() T {
+	if c.Layout != nil {
+		s, _, _ := c.Layout.kind(SegmentTimestamp)
+
+		return T(s.Bits)
+	}
+
+	used := c.ServerBits + c.ProcessBits + c.SequenceBits
+	if used >= width[T]() {
+		return 0
+	}
+
+	return width[T]() - used
+}
+
+// tick returns c.TickDuration or its default when it is not set.
+func (c *Config[T]) tick() time.Duration {
+	if c.TickDuration <= 0 {
+		return defaultTick[T]()
+	}
+
+	return c.TickDuration
+}
+
+// epoch returns c.Epoch as time.
+func (c *Config[T]) epoch() time.Time {
+	return time.Unix(int64(c.Epoch), 0)
+}
+
+// clock returns c.Clock or SystemClock when it is not set.
+func (c *Config[T]) clock() Clock {
+	if c.Clock == nil {
+		return SystemClock{}
+	}
+
+	return c.Clock
+}
+
+// until returns how long until t by the clock of c.
+func (c *Config[T]) until(t time.Time) time.Duration {
+	return t.Sub(c.clock().Now())
+}
+
+// now returns the wall-clock ticks elapsed since c.Epoch.
+func (c *Config[T]) now() T {
+	elapsed := c.clock().Now().Sub(c.epoch())
+	if elapsed < 0 {
+		return 0
+	}
+
+	return T(elapsed / c.tick())
+}
+
+// tickTime returns the wall-clock time of t ticks after c.Epoch.
+func (c *Config[T]) tickTime(t T) time.Time {
+	return c.epoch().Add(time.Duration(t) * c.tick())
+}
+
+// Next generates an id from serverID and processID, unlike Uint32 and Uint64 nothing is silently replaced:
+// ErrServerIDOutOfRange and ErrProcessIDOutOfRange are returned for ids that do not fit their bits,
+// ErrSequenceExhausted when the sequence of the current tick is used up,
+// ErrTimestampOverflow once the layout outlives its Lifetime
+// and ErrClockMovedBackwards under RollbackError.
+func (c *Config[T]) Next(serverID, processID T) (T, error) {
+	if err := c.checkIDs(serverID, processID); err != nil {
+		return 0, err
+	}
+
+	return c.next(context.Background(), c.base(serverID, processID), false)
+}
+
+// NextContext is like Next, besides it waits for the next tick instead of returning ErrSequenceExhausted,
+// ctx.Err() is returned once ctx is done while waiting.
+func (c *Config[T]) NextContext(ctx context.Context, serverID, processID T) (T, error) {
+	if err := c.checkIDs(serverID, processID); err != nil {
+		return 0, err
+	}
+
+	return c.nextContext(ctx, c.base(serverID, processID))
+}
+
+// nextContext generates an id from base, see c.base,
+// waiting for the next tick while the sequence of the current one is exhausted.
+func (c *Config[T]) nextContext(ctx context.Context, base T) (T, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		id, err := c.next(ctx, base, false)
+		if !errors.Is(err, ErrSequenceExhausted) {
+			return id, err
+		}
+
+		c.Lock()
+		wait := c.until(c.tickTime(c.LastTime + 1))
+		c.Unlock()
+
+		if err := c.sleep(ctx, wait); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// checkIDs reports whether serverID and processID fit in their bits.
+func (c *Config[T]) checkIDs(serverID, processID T) error {
+	if serverID > mask(c.ServerBits) {
+		return fmt.Errorf("%w: %d does not fit in %d bits", ErrServerIDOutOfRange, serverID, c.ServerBits)
+	}
+
+	if processID > mask(c.ProcessBits) {
+		return fmt.Errorf("%w: %d does not fit in %d bits", ErrProcessIDOutOfRange, processID, c.ProcessBits)
+	}
+
+	return nil
+}
+
+// next generates an id from base, see c.base, under c's lock,
+// c.RollbackPolicy is applied when the clock moved backwards.
+// An exhausted sequence borrows the next tick when borrow is set, otherwise it is an error,
+// borrowing waits while the next tick is more than MaxDrift ahead of the clock.
+func (c *Config[T]) next(ctx context.Context, base T, borrow bool) (T, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	for {
+		if err := c.readClock(ctx); err != nil {
+			return 0, err
+		}
+
+		// the sequence keeps counting across ticks when the layout leaves
+		// no bits for the timestamp, otherwise ids would repeat every tick.
+		if c.CustomEpoch <= c.LastTime || c.timestampBitsMask() == 0 {
+			switch {
+			case c.Sequence < c.maxSequence():
+				c.Sequence++
+			case borrow && c.LastTime >= c.timestampBitsMask():
+				return 0, fmt.Errorf("%w: cannot borrow past the last tick %d", ErrTimestampOverflow, c.LastTime)
+			case borrow && c.driftWait(c.LastTime+1) > 0:
+				if err := c.sleepUnlocked(ctx, c.driftWait(c.LastTime+1)); err != nil {
+					return 0, err
+				}
+
+				continue
+			case borrow:
+				c.Sequence = 0
+				c.LastTime++
+			default:
+				return 0, fmt.Errorf("%w: %d ids issued in tick %d", ErrSequenceExhausted, c.Sequence+1, c.LastTime)
+			}
+		} else {
+			c.Sequence = 0
+			c.LastTime = c.CustomEpoch
+		}
+
+		if err := c.persist(c.LastTime); err != nil {
+			return 0, err
+		}
+
+		return c.packBase(c.LastTime, base, c.Sequence), nil
+	}
+}
+
+// maxSequence returns the largest sequence next issues in a tick.
+func (c *Config[T]) maxSequence() T {
+	if c.laneMask != 0 {
+		return c.laneMask
+	}
+
+	return mask(c.SequenceBits)
+}
+
+// Drift returns how far the latest id is ahead of the clock, zero unless ids borrowed
+// the following ticks or the clock moved backwards, see MaxDrift.
+func (c *Config[T]) Drift() time.Duration {
+	c.Lock()
+	defer c.Unlock()
+
+	now := c.now()
+	if c.LastTime <= now {
+		return 0
+	}
+
+	return time.Duration(c.LastTime-now) * c.tick()
+}
+
+// driftWait returns how long until tick t is within MaxDrift of the clock,
+// zero when it already is or MaxDrift is not set.
+func (c *Config[T]) driftWait(t T) time.Duration {
+	if c.MaxDrift <= 0 {
+		return 0
+	}
+
+	if wait := c.until(c.tickTime(t).Add(-c.MaxDrift)); wait > 0 {
+		return wait
+	}
+
+	return 0
+}
+
+// sleepUnlocked releases c's lock while sleep pauses for d.
+func (c *Config[T]) sleepUnlocked(ctx context.Context, d time.Duration) error {
+	c.Unlock()
+	defer c.Lock()
+
+	return c.sleep(ctx, d)
+}
+
+// readClock sets c.CustomEpoch to the current tick under c's lock,
+// waiting under RollbackBlock ends early with ctx.Err() once ctx is done,
+// c.RollbackPolicy is applied when the clock reads behind c.clockHigh.
+func (c *Config[T]) readClock(ctx context.Context) error {
+	c.CustomEpoch = c.now()
+
+	// the clock past the timestamp bits would wrap ids around,
+	// unless the layout has no timestamp bits and the sequence alone counts the ids.
+	if c.CustomEpoch > c.timestampBitsMask() && c.timestampBitsMask() != 0 {
+		return fmt.Errorf("%w: the timestamp bits expired at %s", ErrTimestampOverflow, c.Expires())
+	}
+
+	for c.CustomEpoch < c.clockHigh && c.RollbackPolicy == RollbackBlock {
+		if err := c.sleepUnlocked(ctx, c.until(c.tickTime(c.clockHigh))); err != nil {
+			return err
+		}
+
+		c.CustomEpoch = c.now()
+	}
+
+	if c.CustomEpoch < c.clockHigh && c.RollbackPolicy == RollbackError {
+		return fmt.Errorf("%w: clock reads %s behind its latest reading",
+			ErrClockMovedBackwards, time.Duration(c.clockHigh-c.CustomEpoch)*c.tick())
+	}
+
+	c.clockHigh = max(c.clockHigh, c.CustomEpoch)
+
+	return nil
+}
+
+// pack places timestamp, serverID, processID and sequence into their fields of an id,
+// each value is masked to the bits of its field so it cannot spill into a neighbour.
+func (c *Config[T]) pack(timestamp, serverID, processID, sequence T) T {
+	return c.packBase(timestamp, c.base(serverID, processID), sequence)
+}
+
+// packBase places timestamp and sequence into base, an id made by c.base.
+func (c *Config[T]) packBase(timestamp, base, sequence T) T {
+	return timestamp<<c.offset(SegmentTimestamp) | base | (sequence&mask(c.SequenceBits))<<c.offset(SegmentSequence)
+}
+
+// base places serverID, processID and the values of WithSegmentValue into an id
+// lacking the timestamp and the sequence, which only change per call.
+func (c *Config[T]) base(serverID, processID T) T {
+	return c.fixed |
+		(serverID&mask(c.ServerBits))<<c.offset(SegmentServer) |
+		(processID&mask(c.ProcessBits))<<c.offset(SegmentProcess)
+}
+
+// unpack is the reverse of pack.
+func (c *Config[T]) unpack(id T) (timestamp, serverID, processID, sequence T) {
+	timestamp = id >> c.offset(SegmentTimestamp) & c.timestampBitsMask()
+	serverID = id >> c.offset(SegmentServer) & mask(c.ServerBits)
+	processID = id >> c.offset(SegmentProcess) & mask(c.ProcessBits)
+	sequence = id >> c.offset(SegmentSequence) & mask(c.SequenceBits)
+
+	return timestamp, serverID, processID, sequence
+}
+
+// Parts holds the fields an id is made of.
+type Parts[T ID] struct {
+	Time time.Time
+
+	// DatacenterID is zero unless the Layout of the config has a datacenter segment.
+	DatacenterID T
+
+	// Type is the name registered by WithType for the type segment, empty when there is none.
+	Type string
+
+	ServerID,
+	ProcessID,
+	Sequence T
+
+	// Segments holds every segment but the timestamp by name, for configs with a Layout only.
+	Segments map[string]T
+}
+
+// Decode splits id back into its timestamp, datacenterID, serverID, processID, sequence and type
+// using the layout, the Epoch, the TickDuration and the types of c.
+func (c *Config[T]) Decode(id T) Parts[T] {
+	timestamp, serverID, processID, sequence := c.unpack(id)
+
+	parts := Parts[T]{
+		Time:         c.tickTime(timestamp),
+		DatacenterID: c.segment(SegmentDatacenter, id),
+		ServerID:     serverID,
+		ProcessID:    processID,
+		Sequence:     sequence,
+		Type:         c.types.names[c.segment(SegmentType, id)],
+	}
+
+	if c.Layout != nil {
+		parts.Segments = make(map[string]T, len(c.Layout.segments)-1)
+
+		for i, s := range c.Layout.segments {
+			if s.Kind != SegmentTimestamp {
+				parts.Segments[s.Name] = id >> c.Layout.offsets[i] & mask(T(s.Bits))
+			}
+		}
+	}
+
+	return parts
+}
+
+// mask returns a value with the lowest n bits set.
+func mask[T ID](n T) T {
+	return 1<<n - 1
+}
+
+// width returns the number of bits of T.
+func width[T ID]() T {
+	return T(bits.Len64(uint64(^T(0))))
+}
+
+// minTimestampBits returns the fewest timestamp bits a layout of T may leave.
+func minTimestampBits[T ID]() T {
+	if width[T]() == 32 {
+		return minUint32TimestampBits
+	}
+
+	return T(minUint64TimestampBits)
+}
+
+// defaultTick returns the timestamp resolution of T unless a config sets its own.
+func defaultTick[T ID]() time.Duration {
+	if width[T]() == 32 {
+		return defaultUint32Tick
+	}
+
+	return defaultUint64Tick
+}
+
+// sleep pauses for d by the clock of c or until ctx is done, whichever happens first,
+// it returns ctx.Err() in the latter case.
+func (c *Config[T]) sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.clock().After(d):
+		return nil
+	}
+}
@@ -0,0 +1,221 @@
+package oneid
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/coderme/oneid/v3/oneidtest"
+)
+
+// TestConfigRollbackPolicy tests each RollbackPolicy when the clock reads behind a time it has already read.
+func TestConfigRollbackPolicy(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now().Truncate(time.Second)
+
+	for _, p := range []RollbackPolicy{RollbackBorrow, RollbackBlock, RollbackError} {
+		clk32 := oneidtest.NewClock(start)
+
+		// second ticks keep the rollback short.
+		c32, err := NewUint32ConfigWithOptions(4, 2, 8, WithRollbackPolicy(p), WithClock(clk32),
+			WithTick(time.Second), WithEpoch(start.Add(-time.Minute)))
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		testRollbackPolicy(t, &c32, clk32)
+
+		clk64 := oneidtest.NewClock(start)
+
+		c64, err := NewUint64ConfigWithOptions(10, 5, 17, WithRollbackPolicy(p), WithClock(clk64))
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		testRollbackPolicy(t, &c64, clk64)
+	}
+}
+
+// testRollbackPolicy moves clk a second backwards after an id of c, whose clock it is.
+func testRollbackPolicy[T ID](t *testing.T, c *Config[T], clk *oneidtest.Clock) {
+	t.Helper()
+
+	p := c.RollbackPolicy
+
+	prev, err := c.Next(1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	last := c.LastTime
+
+	clk.Set(clk.Now().Add(-time.Second))
+
+	if p == RollbackBlock {
+		go func() {
+			for clk.Waiters() == 0 {
+				time.Sleep(time.Millisecond)
+			}
+
+			clk.Advance(time.Second)
+		}()
+	}
+
+	id, err := c.Next(1, 1)
+
+	switch p {
+	case RollbackBorrow, RollbackBlock:
+		if err != nil {
+			t.Error(p, "unexpected error:", err)
+		}
+
+		if id <= prev || c.LastTime < last {
+			t.Error(p, "timestamp", c.LastTime, "is behind the latest", last)
+		}
+
+		if p == RollbackBlock && c.now() < last {
+			t.Error(p, "returned before the clock caught up")
+		}
+	case RollbackError:
+		if !errors.Is(err, ErrClockMovedBackwards) {
+			t.Error(p, "expected ErrClockMovedBackwards, found:", err)
+		}
+	}
+}
+
+// TestConfigNext tests Next reports out of range ids and an exhausted sequence.
+func TestConfigNext(t *testing.T) {
+	t.Parallel()
+
+	clk := oneidtest.NewClock(time.Now())
+
+	c32, err := NewUint32ConfigWithOptions(4, 2, 8, WithClock(clk))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testConfigNext(t, &c32)
+
+	c64, err := NewUint64ConfigWithOptions(10, 5, 4, WithClock(clk))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testConfigNext(t, &c64)
+}
+
+// testConfigNext expects the clock of c to stand still, so that every call shares its tick.
+func testConfigNext[T ID](t *testing.T, c *Config[T]) {
+	t.Helper()
+
+	if _, err := c.Next(mask(c.ServerBits)+1, 1); !errors.Is(err, ErrServerIDOutOfRange) {
+		t.Error("expected ErrServerIDOutOfRange, found:", err)
+	}
+
+	if _, err := c.Next(1, mask(c.ProcessBits)+1); !errors.Is(err, ErrProcessIDOutOfRange) {
+		t.Error("expected ErrProcessIDOutOfRange, found:", err)
+	}
+
+	if _, err := c.Next(mask(c.ServerBits), mask(c.ProcessBits)); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	for i := 1; i < 1<<c.SequenceBits; i++ {
+		if _, err := c.Next(1, 1); err != nil {
+			t.Fatal("unexpected error at sequence", i, "error:", err)
+		}
+	}
+
+	if _, err := c.Next(1, 1); !errors.Is(err, ErrSequenceExhausted) {
+		t.Error("expected ErrSequenceExhausted, found:", err)
+	}
+}
+
+// TestConfigDecode tests Decode recovers the fields of ids made by Next.
+func TestConfigDecode(t *testing.T) {
+	t.Parallel()
+
+	c32, err := NewUint32ConfigWithOptions(4, 2, 8, WithEpoch(time.Now().Add(-time.Hour)))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testConfigDecode(t, &c32)
+
+	c64, err := NewUint64ConfigWithOptions(10, 5, 17, WithEpoch(time.Now().Add(-time.Hour)))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testConfigDecode(t, &c64)
+}
+
+func testConfigDecode[T ID](t *testing.T, c *Config[T]) {
+	t.Helper()
+
+	before := c.tickTime(c.now())
+
+	for s := T(0); s <= mask(c.ServerBits); s++ {
+		id, err := c.Next(s, s&mask(c.ProcessBits))
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		parts := c.Decode(id)
+		if parts.ServerID != s || parts.ProcessID != s&mask(c.ProcessBits) || parts.Sequence != c.Sequence {
+			t.Error("decoded", parts, "does not match serverID:", s, "sequence:", c.Sequence)
+		}
+
+		if parts.Time.Before(before) || parts.Time.After(time.Now()) {
+			t.Error("decoded time", parts.Time, "is not within", before, "and now")
+		}
+	}
+}
+
+// TestConfigPackRoundTrip tests every serverID, processID and sequence of a layout
+// packs into a distinct id and unpacks back losslessly.
+func TestConfigPackRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	c32, err := NewUint32ConfigWithOptions(3, 3, 4)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testConfigPackRoundTrip(t, &c32)
+
+	c64, err := NewUint64ConfigWithOptions(3, 3, 4)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testConfigPackRoundTrip(t, &c64)
+}
+
+func testConfigPackRoundTrip[T ID](t *testing.T, c *Config[T]) {
+	t.Helper()
+
+	seen := make(map[T]struct{})
+
+	for _, ts := range []T{0, 1, c.timestampBitsMask()} {
+		for s := T(0); s <= mask(c.ServerBits); s++ {
+			for p := T(0); p <= mask(c.ProcessBits); p++ {
+				for q := T(0); q <= mask(c.SequenceBits); q++ {
+					id := c.pack(ts, s, p, q)
+
+					if _, ok := seen[id]; ok {
+						t.Fatal("Duplicate Id found:", id, "timestamp:", ts, "serverID:", s, "processID:", p, "sequence:", q)
+					}
+
+					seen[id] = struct{}{}
+
+					uts, us, up, uq := c.unpack(id)
+					if uts != ts || us != s || up != p || uq != q {
+						t.Fatal("unpacked", uts, us, up, uq, "expected:", ts, s, p, q)
+					}
+				}
+			}
+		}
+	}
+}
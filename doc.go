@@ -41,6 +41,9 @@
 
         The default configration supports upto 1024 servers and upto 32 processes per each one.
 
+        The default uint32 configuration supports upto 16 servers and upto 4 processes per each one,
+        its timestamp counts hours in 16 bits, Lifetime() and Expires() tell how long a layout lasts.
+
         To support more than 1024 servers, or more than 32 processes, consider customizing  processBits and serverBits
         by using NewUint32Config() and NewUint64Config() for Uint32(), EnvUint32() and its uint64 equivalent Uint64()
         EnvUint64() functions respectively.
@@ -0,0 +1,45 @@
+package oneid
+
+import "errors"
+
+var (
+	// ErrEpochInFuture is returned when a config is made with an epoch after the current time.
+	ErrEpochInFuture = errors.New("epoch is in the future")
+
+	// ErrInvalidEpoch is returned when a config is made with an epoch that cannot be represented.
+	ErrInvalidEpoch = errors.New("invalid epoch")
+
+	// ErrInvalidLayout is returned when a config is made with an impossible bits layout.
+	ErrInvalidLayout = errors.New("invalid bits layout")
+
+	// ErrClockMovedBackwards is returned under RollbackError when the clock reads
+	// behind a time it has already read.
+	ErrClockMovedBackwards = errors.New("clock moved backwards")
+
+	// ErrSequenceExhausted is returned when all the sequence numbers of the current time are used.
+	ErrSequenceExhausted = errors.New("sequence exhausted")
+
+	// ErrDriftExceeded is returned when ids would borrow ticks further ahead of the clock than MaxDrift.
+	ErrDriftExceeded = errors.New("max drift exceeded")
+
+	// ErrTimestampOverflow is returned once the clock outgrows the timestamp bits of a layout.
+	ErrTimestampOverflow = errors.New("timestamp overflow")
+
+	// ErrServerIDOutOfRange is returned when a serverID does not fit in ServerBits.
+	ErrServerIDOutOfRange = errors.New("serverID out of range")
+
+	// ErrProcessIDOutOfRange is returned when a processID does not fit in ProcessBits.
+	ErrProcessIDOutOfRange = errors.New("processID out of range")
+
+	// ErrDatacenterIDOutOfRange is returned when a datacenterID does not fit in the bits of its segment.
+	ErrDatacenterIDOutOfRange = errors.New("datacenterID out of range")
+
+	// ErrUnknownType is returned when a type name or tag is not registered by WithType.
+	ErrUnknownType = errors.New("unknown type")
+
+	// ErrTypeMismatch is returned when an id does not refer to the expected type.
+	ErrTypeMismatch = errors.New("type mismatch")
+
+	// ErrSegmentValueOutOfRange is returned when a value does not fit in the bits of its segment.
+	ErrSegmentValueOutOfRange = errors.New("segment value out of range")
+)
@@ -0,0 +1,85 @@
+package oneid
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// IDGenerator is the interface of anything that generates ids,
+// depend on it rather than on Generator to swap or mock the generation.
+type IDGenerator[T ID] interface {
+	Next() (T, error)
+}
+
+// Generator generates ids from a Config for the serverID and processID bound at construction,
+// it is cocurrently-safe and generators of the same Config share its sequence.
+//
+// The ids of a Generator are strictly increasing, an id returned after another is greater,
+// even when returned to different goroutines.
+type Generator[T ID] struct {
+	config    *Config[T]
+	serverID  T
+	processID T
+
+	// base is the id of serverID and processID without timestamp and sequence.
+	base T
+}
+
+// NewGenerator binds serverID and processID to c, returning ErrServerIDOutOfRange
+// or ErrProcessIDOutOfRange once here instead of on every call.
+func NewGenerator[T ID](c *Config[T], serverID, processID T) (*Generator[T], error) {
+	if err := c.checkIDs(serverID, processID); err != nil {
+		return nil, err
+	}
+
+	return &Generator[T]{
+		config:    c,
+		serverID:  serverID,
+		processID: processID,
+		base:      c.base(serverID, processID),
+	}, nil
+}
+
+// NewEnvGenerator is like NewGenerator, besides it reads serverID and processID
+// from the environment variables SERVER_ID and PROCESS_ID.
+func NewEnvGenerator[T ID](c *Config[T]) (*Generator[T], error) {
+	serverID, err := strconv.ParseUint(os.Getenv(serverIDKey), 10, int(width[T]()))
+	if err != nil {
+		return nil, fmt.Errorf("parsing serverID from env("+serverIDKey+") -> %w", err)
+	}
+
+	processID, err := strconv.ParseUint(os.Getenv(processIDKey), 10, int(width[T]()))
+	if err != nil {
+		return nil, fmt.Errorf("parsing processID from env("+processIDKey+") -> %w", err)
+	}
+
+	return NewGenerator(c, T(serverID), T(processID))
+}
+
+// Next generates an id, it returns the same errors as Config.Next.
+func (g *Generator[T]) Next() (T, error) {
+	return g.config.next(context.Background(), g.base, false)
+}
+
+// NextContext is like Next, besides it waits for the next tick instead of returning ErrSequenceExhausted,
+// ctx.Err() is returned once ctx is done while waiting.
+func (g *Generator[T]) NextContext(ctx context.Context) (T, error) {
+	return g.config.nextContext(ctx, g.base)
+}
+
+// Config returns the config g generates ids from, e.g. to Decode them.
+func (g *Generator[T]) Config() *Config[T] {
+	return g.config
+}
+
+// ServerID returns the serverID bound to g.
+func (g *Generator[T]) ServerID() T {
+	return g.serverID
+}
+
+// ProcessID returns the processID bound to g.
+func (g *Generator[T]) ProcessID() T {
+	return g.processID
+}
@@ -0,0 +1,364 @@
+package oneid
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// compile-time checks that Generator satisfies IDGenerator for both id types.
+var (
+	_ IDGenerator[uint32] = (*Generator[uint32])(nil)
+	_ IDGenerator[uint64] = (*Generator[uint64])(nil)
+)
+
+// TestNewGenerator tests NewGenerator rejects ids that do not fit the layout.
+func TestNewGenerator(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 17)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := NewGenerator(&c, 1024, 1); !errors.Is(err, ErrServerIDOutOfRange) {
+		t.Error("expected ErrServerIDOutOfRange, found:", err)
+	}
+
+	if _, err := NewGenerator(&c, 1, 32); !errors.Is(err, ErrProcessIDOutOfRange) {
+		t.Error("expected ErrProcessIDOutOfRange, found:", err)
+	}
+
+	g, err := NewGenerator(&c, 1024-1, 32-1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if g.ServerID() != 1024-1 || g.ProcessID() != 32-1 || g.Config() != &c {
+		t.Error("generator does not hold the config and ids it was made with")
+	}
+}
+
+// TestGeneratorNext tests ids of a Generator decode to its bound serverID and processID.
+func TestGeneratorNext(t *testing.T) {
+	t.Parallel()
+
+	c32, err := NewUint32ConfigWithOptions(4, 2, 10)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testGeneratorNext(t, &c32, 5, 3)
+
+	c64, err := NewUint64ConfigWithOptions(10, 5, 17)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testGeneratorNext(t, &c64, 700, 21)
+}
+
+func testGeneratorNext[T ID](t *testing.T, c *Config[T], serverID, processID T) {
+	t.Helper()
+
+	g, err := NewGenerator(c, serverID, processID)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	var gen IDGenerator[T] = g
+
+	ids := newIDChecker(t, c, serverID, processID)
+
+	for i := 0; i < 512; i++ {
+		id, err := gen.Next()
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if !ids.check(id) {
+			t.FailNow()
+		}
+	}
+}
+
+// idChecker fails a test on ids that repeat or do not decode to its serverID and processID,
+// it is safe for concurrent use.
+type idChecker[T ID] struct {
+	t         *testing.T
+	config    *Config[T]
+	serverID  T
+	processID T
+
+	mu  sync.Mutex
+	ids map[T]struct{}
+}
+
+// newIDChecker makes an idChecker for the ids of serverID and processID generated from c.
+func newIDChecker[T ID](t *testing.T, c *Config[T], serverID, processID T) *idChecker[T] {
+	return &idChecker[T]{
+		t:         t,
+		config:    c,
+		serverID:  serverID,
+		processID: processID,
+		ids:       map[T]struct{}{},
+	}
+}
+
+// check reports whether id is new and decodes to the serverID and processID of k,
+// the test is marked failed otherwise.
+func (k *idChecker[T]) check(id T) bool {
+	parts := k.config.Decode(id)
+	if parts.ServerID != k.serverID || parts.ProcessID != k.processID {
+		k.t.Error("decoded", parts, "does not match serverID:", k.serverID, "processID:", k.processID)
+
+		return false
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.ids[id]; ok {
+		k.t.Error("duplicate id:", id)
+
+		return false
+	}
+
+	k.ids[id] = struct{}{}
+
+	return true
+}
+
+// TestGeneratorsShareConfig tests generators of the same config never collide across goroutines.
+func TestGeneratorsShareConfig(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 17)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	const n = 8
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		ids = map[uint64]struct{}{}
+	)
+
+	for s := uint64(0); s < n; s++ {
+		g, err := NewGenerator(&c, s%2, 1)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 1000; i++ {
+				id, err := g.Next()
+				if err != nil {
+					t.Error("unexpected error:", err)
+
+					return
+				}
+
+				mu.Lock()
+				if _, ok := ids[id]; ok {
+					t.Error("duplicate id:", id)
+				}
+				ids[id] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// TestGeneratorNextContext tests NextContext waits for the next tick once the sequence is exhausted.
+func TestGeneratorNextContext(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 4, WithTick(10*time.Millisecond))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewGenerator(&c, 1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	var prev uint64
+
+	// 100 ids need at least 7 ticks of 16 sequence numbers.
+	for i := 0; i < 100; i++ {
+		id, err := g.NextContext(context.Background())
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if id <= prev {
+			t.Fatal("id", id, "is not after", prev)
+		}
+
+		prev = id
+	}
+
+	if c.LastTime > c.now() {
+		t.Error("latest tick", c.LastTime, "is ahead of the clock", c.now())
+	}
+}
+
+// TestGeneratorNextContextCanceled tests NextContext returns ctx.Err() while waiting.
+func TestGeneratorNextContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []RollbackPolicy{RollbackBorrow, RollbackBlock} {
+		c, err := NewUint64ConfigWithOptions(10, 5, 4, WithRollbackPolicy(p))
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		// exhaust a tick a minute ahead of the clock.
+		c.LastTime = c.now() + 60
+		c.Sequence = 1<<c.SequenceBits - 1
+
+		g, err := NewGenerator(&c, 1, 1)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+
+		if _, err := g.NextContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Error(p, "expected context.DeadlineExceeded, found:", err)
+		}
+
+		cancel()
+
+		if _, err := g.NextContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Error(p, "expected context.DeadlineExceeded for a done context, found:", err)
+		}
+	}
+}
+
+// testMonotonic calls next from concurrent goroutines and asserts the ids are strictly increasing:
+// per goroutine, across the goroutines taking turns under a lock, and without duplicates overall.
+// ErrSequenceExhausted is retried.
+func testMonotonic[T ID](t *testing.T, next func() (T, error)) {
+	t.Helper()
+
+	const (
+		goroutines   = 8
+		perGoroutine = 2000
+	)
+
+	var (
+		wg     sync.WaitGroup
+		turns  sync.Mutex
+		latest T
+		issued = make([][]T, goroutines)
+	)
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+
+		// half of the goroutines take turns, so their ids are ordered against each other,
+		// the other half keep contending freely.
+		go func(g int, inTurns bool) {
+			defer wg.Done()
+
+			var prev T
+
+			for len(issued[g]) < perGoroutine {
+				if inTurns {
+					turns.Lock()
+				}
+
+				id, err := next()
+
+				if err == nil && inTurns {
+					if id <= latest {
+						t.Errorf("id %d issued after %d", id, latest)
+					}
+
+					latest = id
+				}
+
+				if inTurns {
+					turns.Unlock()
+				}
+
+				if errors.Is(err, ErrSequenceExhausted) {
+					continue
+				}
+
+				if err != nil {
+					t.Error("unexpected error:", err)
+
+					return
+				}
+
+				if id <= prev {
+					t.Errorf("id %d issued after %d in the same goroutine", id, prev)
+
+					return
+				}
+
+				prev = id
+				issued[g] = append(issued[g], id)
+			}
+		}(g, g%2 == 0)
+	}
+
+	wg.Wait()
+
+	seen := make(map[T]struct{}, goroutines*perGoroutine)
+
+	for _, ids := range issued {
+		for _, id := range ids {
+			if _, ok := seen[id]; ok {
+				t.Fatal("duplicate id:", id)
+			}
+
+			seen[id] = struct{}{}
+		}
+	}
+}
+
+// TestGeneratorMonotonic tests the ids of a Generator are strictly increasing across ticks under contention.
+func TestGeneratorMonotonic(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 4, WithTick(time.Millisecond))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewGenerator(&c, 1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testMonotonic(t, g.Next)
+	testMonotonic(t, func() (uint64, error) { return g.NextContext(context.Background()) })
+
+	// 16 bits of milliseconds last about a minute from the epoch.
+	c32, err := NewUint32ConfigWithOptions(4, 2, 10, WithTick(time.Millisecond), WithEpoch(time.Now().Add(-time.Second)))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g32, err := NewGenerator(&c32, 1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testMonotonic(t, g32.Next)
+}
@@ -1,5 +1,5 @@
 module github.com/coderme/oneid/v3
 
 
-go 1.18
+go 1.23
 
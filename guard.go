@@ -0,0 +1,22 @@
+package oneid
+
+import "time"
+
+// waitStartup blocks until the clock of c has moved past the tick it reads now and at least window has passed,
+// so ids cannot overlap the ones a crashed predecessor issued in that tick or within window.
+func (c *Config[T]) waitStartup(window time.Duration) {
+	clk := c.clock()
+	now := clk.Now()
+
+	ticks := T(0)
+	if elapsed := now.Sub(c.epoch()); elapsed > 0 {
+		ticks = T(elapsed / c.tick())
+	}
+
+	until := c.tickTime(ticks + 1)
+	if w := now.Add(window); w.After(until) {
+		until = w
+	}
+
+	<-clk.After(until.Sub(now))
+}
@@ -0,0 +1,63 @@
+package oneid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coderme/oneid/v3/oneidtest"
+)
+
+// TestStartupGuard tests the startup guard waits past the current tick or the window, whichever is later.
+func TestStartupGuard(t *testing.T) {
+	t.Parallel()
+
+	epoch := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		window time.Duration
+		wait   time.Duration
+	}{
+		{name: "rest of the tick", now: epoch.Add(10*time.Second + 300*time.Millisecond), wait: 700 * time.Millisecond},
+		{name: "tick start", now: epoch.Add(10 * time.Second), wait: time.Second},
+		{name: "window", now: epoch.Add(10*time.Second + 300*time.Millisecond), window: 5 * time.Second, wait: 5 * time.Second},
+		{name: "short window", now: epoch.Add(10*time.Second + 300*time.Millisecond), window: time.Millisecond, wait: 700 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		clk := oneidtest.NewClock(tt.now)
+		done := make(chan error)
+
+		go func() {
+			_, err := NewUint64ConfigWithOptions(10, 5, 17, WithEpoch(epoch), WithStartupGuard(tt.window), WithClock(clk))
+			done <- err
+		}()
+
+		for clk.Waiters() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		clk.Advance(tt.wait - time.Nanosecond)
+
+		if clk.Waiters() != 1 {
+			t.Fatal(tt.name, "returned before waiting", tt.wait)
+		}
+
+		clk.Advance(time.Nanosecond)
+
+		if err := <-done; err != nil {
+			t.Fatal(tt.name, "unexpected error:", err)
+		}
+	}
+
+	if _, err := NewUint64ConfigWithOptions(10, 5, 17, WithStartupGuard(-time.Second)); err == nil {
+		t.Error("expected an error for a negative window")
+	}
+
+	clk := oneidtest.NewClock(time.Now())
+
+	if _, err := NewUint64ConfigWithOptions(10, 5, 17, WithClock(clk)); err != nil || clk.Waiters() != 0 {
+		t.Error("expected no wait without the startup guard, found:", clk.Waiters(), err)
+	}
+}
@@ -0,0 +1,71 @@
+package oneid
+
+import (
+	"context"
+	"fmt"
+)
+
+// HLCGenerator generates ids as a hybrid logical clock: the timestamp follows the clock,
+// yet never falls behind the ids observed from other nodes, whose sequence it continues.
+// Ids share the layout of the config and Decode as usual.
+//
+// Causality holds on the timestamp and the sequence of ids, the serverID and the processID
+// in between make numeric order break ties within a tick, so compare the decoded parts:
+// an id issued after observing another decodes to a later timestamp, or the same one with a greater sequence.
+type HLCGenerator[T ID] struct {
+	config *Config[T]
+	base   T
+}
+
+// NewHLCGenerator binds serverID and processID to c like NewGenerator,
+// c must use RollbackBorrow since observed ids are often ahead of the local clock.
+func NewHLCGenerator[T ID](c *Config[T], serverID, processID T) (*HLCGenerator[T], error) {
+	if err := c.checkIDs(serverID, processID); err != nil {
+		return nil, err
+	}
+
+	if c.RollbackPolicy != RollbackBorrow {
+		return nil, fmt.Errorf("hlc generators need %s, found %s", RollbackBorrow, c.RollbackPolicy)
+	}
+
+	return &HLCGenerator[T]{
+		config: c,
+		base:   c.base(serverID, processID),
+	}, nil
+}
+
+// Next generates an id after the latest id of g and the ids it observed,
+// an exhausted sequence borrows the next tick as Uint64 does, up to MaxDrift.
+func (g *HLCGenerator[T]) Next() (T, error) {
+	return g.config.next(context.Background(), g.base, true)
+}
+
+// Observe advances g past id, an id received from another node, so the next ids of g come after it.
+// Ids behind the latest id of g have no effect, while ids further ahead of the clock
+// than MaxDrift are rejected with ErrDriftExceeded.
+func (g *HLCGenerator[T]) Observe(id T) error {
+	c := g.config
+	timestamp, _, _, sequence := c.unpack(id)
+
+	c.Lock()
+	defer c.Unlock()
+
+	if d := c.driftWait(timestamp); d > 0 {
+		return fmt.Errorf("%w: observed id is %s ahead of the clock, %s at most",
+			ErrDriftExceeded, d+c.MaxDrift, c.MaxDrift)
+	}
+
+	if timestamp < c.LastTime || timestamp == c.LastTime && sequence <= c.Sequence {
+		return nil
+	}
+
+	c.LastTime = timestamp
+	c.Sequence = sequence
+
+	return c.persist(c.LastTime)
+}
+
+// Config returns the config g generates ids from, e.g. to Decode them.
+func (g *HLCGenerator[T]) Config() *Config[T] {
+	return g.config
+}
@@ -0,0 +1,124 @@
+package oneid
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/coderme/oneid/v3/oneidtest"
+)
+
+var _ IDGenerator[uint64] = (*HLCGenerator[uint64])(nil)
+
+// hlcAfter reports whether id a of c comes after id b by timestamp and sequence.
+func hlcAfter(c *Uint64Config, a, b uint64) bool {
+	pa, pb := c.Decode(a), c.Decode(b)
+
+	return pa.Time.After(pb.Time) || pa.Time.Equal(pb.Time) && pa.Sequence > pb.Sequence
+}
+
+// TestHLCGeneratorObserve tests ids exchanged between nodes with skewed clocks respect causality.
+func TestHLCGeneratorObserve(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now().Truncate(time.Second)
+	epoch := WithEpoch(start.Add(-time.Hour))
+
+	// the clock of a runs 10 seconds ahead of the one of b.
+	ca, err := NewUint64ConfigWithOptions(10, 5, 17, epoch, WithClock(oneidtest.NewClock(start.Add(10*time.Second))))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	cb, err := NewUint64ConfigWithOptions(10, 5, 17, epoch, WithClock(oneidtest.NewClock(start)))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	a, err := NewHLCGenerator(&ca, 2, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	b, err := NewHLCGenerator(&cb, 1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	sent, err := a.Next()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// b ignores its own ids, then receives the id of a.
+	for i := 0; i < 3; i++ {
+		if _, err := b.Next(); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	if err := b.Observe(sent); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	reply, err := b.Next()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !hlcAfter(&cb, reply, sent) {
+		t.Error("reply", cb.Decode(reply), "is not after the observed", ca.Decode(sent))
+	}
+
+	// an older id has no effect.
+	last := cb.LastTime
+
+	if err := b.Observe(cb.pack(last-5, 3, 1, 100)); err != nil || cb.LastTime != last {
+		t.Error("expected an older id to be ignored, found:", cb.LastTime, err)
+	}
+
+	if err := a.Observe(reply); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	next, err := a.Next()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !hlcAfter(&ca, next, reply) {
+		t.Error("id", ca.Decode(next), "is not after the observed", cb.Decode(reply))
+	}
+}
+
+// TestHLCGeneratorErrors tests HLCGenerator requires RollbackBorrow and bounds observed ids by MaxDrift.
+func TestHLCGeneratorErrors(t *testing.T) {
+	t.Parallel()
+
+	blocking, err := NewUint64ConfigWithOptions(10, 5, 17, WithRollbackPolicy(RollbackBlock))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := NewHLCGenerator(&blocking, 1, 1); err == nil {
+		t.Error("expected an error for RollbackBlock")
+	}
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 17, WithMaxDrift(5*time.Second))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewHLCGenerator(&c, 1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := g.Observe(c.pack(c.now()+60, 2, 1, 0)); !errors.Is(err, ErrDriftExceeded) {
+		t.Error("expected ErrDriftExceeded, found:", err)
+	}
+
+	if err := g.Observe(c.pack(c.now()+2, 2, 1, 0)); err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
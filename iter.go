@@ -0,0 +1,75 @@
+package oneid
+
+import (
+	"context"
+	"iter"
+)
+
+// maxBlockSize is the most ids All reserves at once.
+const maxBlockSize = 1024
+
+// reserveBlock reserves the next block of at most n ids for All,
+// only borrowing the following ticks within MaxDrift.
+func (g *Generator[T]) reserveBlock(ctx context.Context, n int) (Range[T], error) {
+	g.config.Lock()
+	borrow := g.config.MaxDrift > 0
+	g.config.Unlock()
+
+	if borrow {
+		return g.reserve(ctx, n, true)
+	}
+
+	return g.reserveTick(ctx, n)
+}
+
+// All returns an iterator yielding ids from blocks reserved at once,
+// so the lock of the config is taken once per block rather than once per id.
+// Once the sequence of the current tick is used up All waits for the next tick like NextContext,
+// unless MaxDrift is set, then the following ticks are borrowed up to MaxDrift ahead of the clock.
+//
+// The iteration stops when ctx is done or an id cannot be generated,
+// the reason is yielded last with a zero id.
+//
+//	for id, err := range gen.All(ctx) {
+//		if err != nil {
+//			break // deal with the error
+//		}
+//		// use id
+//	}
+func (g *Generator[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		size := maxBlockSize
+		if seq := uint64(mask(g.config.SequenceBits)) + 1; seq < maxBlockSize {
+			size = int(seq)
+		}
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(0, err)
+
+				return
+			}
+
+			r, err := g.reserveBlock(ctx, size)
+			if err != nil {
+				yield(0, err)
+
+				return
+			}
+
+			for i := 0; i < r.Len(); i++ {
+				select {
+				case <-ctx.Done():
+					yield(0, ctx.Err())
+
+					return
+				default:
+				}
+
+				if !yield(r.At(i), nil) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package oneid
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/coderme/oneid/v3/oneidtest"
+)
+
+// TestGeneratorAll tests All yields unique ascending ids until the loop breaks.
+func TestGeneratorAll(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 17)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewGenerator(&c, 700, 21)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	var (
+		prev uint64
+		n    int
+		ids  = newIDChecker(t, &c, 700, 21)
+	)
+
+	for id, err := range g.All(context.Background()) {
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if id <= prev {
+			t.Fatal("id", id, "is not after", prev)
+		}
+
+		if !ids.check(id) {
+			t.FailNow()
+		}
+
+		prev = id
+		n++
+
+		if n == 5000 {
+			break
+		}
+	}
+
+	if n != 5000 {
+		t.Error("expected 5000 ids, found:", n)
+	}
+}
+
+// TestGeneratorAllDrift tests All waits for the clock rather than borrowing ticks beyond MaxDrift,
+// which is none when it is not set.
+func TestGeneratorAllDrift(t *testing.T) {
+	t.Parallel()
+
+	const tick = 10 * time.Millisecond
+
+	for _, maxDrift := range []time.Duration{0, 5 * tick} {
+		c, err := NewUint64ConfigWithOptions(10, 5, 4, WithTick(tick), WithMaxDrift(maxDrift))
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		g, err := NewGenerator(&c, 700, 21)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		var (
+			prev uint64
+			n    int
+		)
+
+		// 500 ids need more than 31 ticks of 16 sequence numbers.
+		for id, err := range g.All(context.Background()) {
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if id <= prev {
+				t.Fatal("id", id, "is not after", prev)
+			}
+
+			if d := c.Drift(); d > max(maxDrift, tick) {
+				t.Fatal("ids run", d, "ahead of the clock, MaxDrift:", maxDrift)
+			}
+
+			prev = id
+			n++
+
+			if n == 500 {
+				break
+			}
+		}
+	}
+}
+
+// TestGeneratorAllCanceled tests All stops and reports the error once its context is canceled.
+func TestGeneratorAllCanceled(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 17)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewGenerator(&c, 1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		errAll error
+		n      int
+	)
+
+	for _, err := range g.All(ctx) {
+		if err != nil {
+			errAll = err
+
+			break
+		}
+
+		n++
+
+		if n == 10 {
+			cancel()
+		}
+	}
+
+	if n != 10 {
+		t.Error("expected the iteration to stop after 10 ids, found:", n)
+	}
+
+	if !errors.Is(errAll, context.Canceled) {
+		t.Error("expected context.Canceled, found:", errAll)
+	}
+}
+
+// TestGeneratorAllError tests All stops and reports an error of the generation.
+func TestGeneratorAllError(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+	clk := oneidtest.NewClock(start)
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 17, WithRollbackPolicy(RollbackError), WithClock(clk))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewGenerator(&c, 1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := g.Next(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	clk.Set(start.Add(-time.Minute))
+
+	var errAll error
+
+	for id, err := range g.All(context.Background()) {
+		if err == nil {
+			t.Fatal("unexpected id:", id)
+		}
+
+		errAll = err
+	}
+
+	if !errors.Is(errAll, ErrClockMovedBackwards) {
+		t.Error("expected ErrClockMovedBackwards, found:", errAll)
+	}
+}
@@ -0,0 +1,300 @@
+package oneid
+
+import "fmt"
+
+// SegmentKind tells what a segment of an id holds.
+type SegmentKind uint8
+
+const (
+	// SegmentTimestamp holds the ticks since Epoch, every layout has one as its most significant segment.
+	SegmentTimestamp SegmentKind = iota
+
+	// SegmentDatacenter holds the datacenter or region the id is generated in.
+	SegmentDatacenter
+
+	// SegmentServer holds the serverID.
+	SegmentServer
+
+	// SegmentProcess holds the processID.
+	SegmentProcess
+
+	// SegmentShard holds a shard the id belongs to.
+	SegmentShard
+
+	// SegmentType holds a tag telling the type of entity the id refers to.
+	SegmentType
+
+	// SegmentSequence counts the ids of a tick, every layout has one.
+	SegmentSequence
+
+	// SegmentCustom holds any value of the application, a layout may have several.
+	SegmentCustom
+
+	segmentKinds = iota
+)
+
+// String returns the name of k, used as the name of segments that have none.
+func (k SegmentKind) String() string {
+	switch k {
+	case SegmentTimestamp:
+		return "timestamp"
+	case SegmentDatacenter:
+		return "datacenter"
+	case SegmentServer:
+		return "server"
+	case SegmentProcess:
+		return "process"
+	case SegmentShard:
+		return "shard"
+	case SegmentType:
+		return "type"
+	case SegmentSequence:
+		return "sequence"
+	case SegmentCustom:
+		return "custom"
+	default:
+		return fmt.Sprintf("SegmentKind(%d)", uint8(k))
+	}
+}
+
+// Segment is a named field of Bits bits in an id.
+type Segment struct {
+	Name string
+	Kind SegmentKind
+	Bits uint
+}
+
+// Layout is an ordered list of segments from the most to the least significant bits of an id,
+// configs made by NewConfig generate and decode ids by it.
+type Layout struct {
+	segments []Segment
+	offsets  []uint
+
+	// kinds holds the index plus one of the segment of each kind but SegmentCustom.
+	kinds [segmentKinds]int
+}
+
+// NewLayout makes a Layout of segments, the first one being the most significant.
+// Segments without a name are named after their kind.
+//
+// An error wrapping ErrInvalidLayout is returned unless the first segment is SegmentTimestamp,
+// a SegmentSequence follows, each segment has at least one bit and a unique name,
+// no kind but SegmentCustom repeats and all of them fit in 64 bits.
+func NewLayout(segments ...Segment) (*Layout, error) {
+	l := &Layout{
+		segments: make([]Segment, len(segments)),
+		offsets:  make([]uint, len(segments)),
+	}
+
+	copy(l.segments, segments)
+
+	if len(segments) == 0 || segments[0].Kind != SegmentTimestamp {
+		return nil, fmt.Errorf("%w: the first segment must be the timestamp", ErrInvalidLayout)
+	}
+
+	names := map[string]bool{}
+
+	var total uint
+
+	for i := range l.segments {
+		s := &l.segments[i]
+
+		if s.Name == "" {
+			s.Name = s.Kind.String()
+		}
+
+		switch {
+		case s.Kind >= segmentKinds:
+			return nil, fmt.Errorf("%w: segment %q has unknown kind %s", ErrInvalidLayout, s.Name, s.Kind)
+		case s.Bits == 0:
+			return nil, fmt.Errorf("%w: segment %q cannot have zero bits", ErrInvalidLayout, s.Name)
+		case s.Bits > 64-total:
+			return nil, fmt.Errorf("%w: segments exceed 64 bits at %q", ErrInvalidLayout, s.Name)
+		case names[s.Name]:
+			return nil, fmt.Errorf("%w: segment %q is repeated", ErrInvalidLayout, s.Name)
+		case s.Kind != SegmentCustom && l.kinds[s.Kind] != 0:
+			return nil, fmt.Errorf("%w: segment %q repeats the %s", ErrInvalidLayout, s.Name, s.Kind)
+		}
+
+		names[s.Name] = true
+
+		if s.Kind != SegmentCustom {
+			l.kinds[s.Kind] = i + 1
+		}
+
+		total += s.Bits
+	}
+
+	if l.kinds[SegmentSequence] == 0 {
+		return nil, fmt.Errorf("%w: a sequence segment is needed", ErrInvalidLayout)
+	}
+
+	// offsets count from the least significant bit, so they are summed from the last segment.
+	var offset uint
+
+	for i := len(l.segments) - 1; i >= 0; i-- {
+		l.offsets[i] = offset
+		offset += l.segments[i].Bits
+	}
+
+	return l, nil
+}
+
+// Segments returns the segments of l from the most to the least significant.
+func (l *Layout) Segments() []Segment {
+	segments := make([]Segment, len(l.segments))
+	copy(segments, l.segments)
+
+	return segments
+}
+
+// Bits returns the number of bits all the segments of l take.
+func (l *Layout) Bits() uint {
+	var bits uint
+	for _, s := range l.segments {
+		bits += s.Bits
+	}
+
+	return bits
+}
+
+// kind returns the segment of kind k and its offset, ok is false when l has none.
+func (l *Layout) kind(k SegmentKind) (s Segment, offset uint, ok bool) {
+	i := l.kinds[k]
+	if i == 0 {
+		return Segment{}, 0, false
+	}
+
+	return l.segments[i-1], l.offsets[i-1], true
+}
+
+// named returns the segment called name and its offset, ok is false when l has none.
+func (l *Layout) named(name string) (s Segment, offset uint, ok bool) {
+	for i, s := range l.segments {
+		if s.Name == name {
+			return s, l.offsets[i], true
+		}
+	}
+
+	return Segment{}, 0, false
+}
+
+// offset returns where the segment of kind k starts in the ids of c, counting from the least significant bit.
+func (c *Config[T]) offset(k SegmentKind) T {
+	if c.Layout != nil {
+		_, offset, _ := c.Layout.kind(k)
+
+		return T(offset)
+	}
+
+	switch k {
+	case SegmentTimestamp:
+		return c.ServerBits + c.ProcessBits + c.SequenceBits
+	case SegmentServer:
+		return c.ProcessBits + c.SequenceBits
+	case SegmentProcess:
+		return c.SequenceBits
+	default:
+		return 0
+	}
+}
+
+// validateLayout reports whether c.Layout fits in T and agrees with the bits of c.
+func (c *Config[T]) validateLayout() error {
+	if bits := c.Layout.Bits(); bits > uint(width[T]()) {
+		return fmt.Errorf("%w: segments take %d bits, more than %d", ErrInvalidLayout, bits, width[T]())
+	}
+
+	if ts := c.timestampBits(); ts < minTimestampBits[T]() {
+		return fmt.Errorf("%w: %d bits for the timestamp, at least %d are needed",
+			ErrInvalidLayout, ts, minTimestampBits[T]())
+	}
+
+	for _, f := range []struct {
+		kind SegmentKind
+		bits T
+	}{
+		{SegmentServer, c.ServerBits},
+		{SegmentProcess, c.ProcessBits},
+		{SegmentSequence, c.SequenceBits},
+	} {
+		if s, _, _ := c.Layout.kind(f.kind); T(s.Bits) != f.bits {
+			return fmt.Errorf("%w: %d bits for the %s differ from the %d bits of its segment",
+				ErrInvalidLayout, f.bits, f.kind, s.Bits)
+		}
+	}
+
+	return nil
+}
+
+// setValues places values, keyed by segment name, in c.fixed so every id carries them.
+func (c *Config[T]) setValues(values map[string]uint64) error {
+	for name, v := range values {
+		if c.Layout == nil {
+			return fmt.Errorf("%w: no segment named %q, see NewConfig", ErrInvalidLayout, name)
+		}
+
+		s, offset, ok := c.Layout.named(name)
+		if !ok {
+			return fmt.Errorf("%w: no segment named %q", ErrInvalidLayout, name)
+		}
+
+		switch s.Kind {
+		case SegmentTimestamp, SegmentServer, SegmentProcess, SegmentSequence:
+			return fmt.Errorf("%w: the %s segment %q cannot be set by value", ErrInvalidLayout, s.Kind, name)
+		case SegmentDatacenter:
+			if err := c.setDatacenterID(v); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if v > uint64(mask(T(s.Bits))) {
+			return fmt.Errorf("%w: %d does not fit segment %q of %d bits", ErrSegmentValueOutOfRange, v, name, s.Bits)
+		}
+
+		c.fixed |= T(v) << offset
+	}
+
+	return nil
+}
+
+// setDatacenterID places id in the datacenter segment of c, replacing any value set before.
+func (c *Config[T]) setDatacenterID(id uint64) error {
+	if c.Layout == nil {
+		return fmt.Errorf("%w: no datacenter segment, see NewConfig", ErrInvalidLayout)
+	}
+
+	s, offset, ok := c.Layout.kind(SegmentDatacenter)
+	if !ok {
+		return fmt.Errorf("%w: no datacenter segment", ErrInvalidLayout)
+	}
+
+	if id > uint64(mask(T(s.Bits))) {
+		return fmt.Errorf("%w: %d does not fit in %d bits", ErrDatacenterIDOutOfRange, id, s.Bits)
+	}
+
+	c.fixed = c.fixed&^(mask(T(s.Bits))<<offset) | T(id)<<offset
+
+	return nil
+}
+
+// DatacenterID returns the datacenterID the ids of c carry, see WithDatacenterID.
+func (c *Config[T]) DatacenterID() T {
+	return c.segment(SegmentDatacenter, c.fixed)
+}
+
+// segment returns the value of the segment of kind k in id, zero when c has none.
+func (c *Config[T]) segment(k SegmentKind, id T) T {
+	if c.Layout == nil {
+		return 0
+	}
+
+	s, offset, ok := c.Layout.kind(k)
+	if !ok {
+		return 0
+	}
+
+	return id >> offset & mask(T(s.Bits))
+}
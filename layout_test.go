@@ -0,0 +1,374 @@
+package oneid
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestNewLayout tests NewLayout rejects segments that cannot make an id.
+func TestNewLayout(t *testing.T) {
+	t.Parallel()
+
+	ts := Segment{Kind: SegmentTimestamp, Bits: 32}
+	seq := Segment{Kind: SegmentSequence, Bits: 12}
+
+	for name, segments := range map[string][]Segment{
+		"empty":          nil,
+		"no timestamp":   {seq},
+		"late timestamp": {seq, ts},
+		"no sequence":    {ts, {Kind: SegmentServer, Bits: 8}},
+		"zero bits":      {ts, {Kind: SegmentServer}, seq},
+		"too wide":       {ts, {Kind: SegmentServer, Bits: 21}, seq},
+		"unknown kind":   {ts, {Kind: segmentKinds, Bits: 1}, seq},
+		"repeated kind":  {ts, {Name: "a", Kind: SegmentShard, Bits: 1}, {Name: "b", Kind: SegmentShard, Bits: 1}, seq},
+		"repeated name":  {ts, {Name: "x", Kind: SegmentCustom, Bits: 1}, {Name: "x", Kind: SegmentCustom, Bits: 1}, seq},
+	} {
+		if _, err := NewLayout(segments...); !errors.Is(err, ErrInvalidLayout) {
+			t.Error(name, "expected ErrInvalidLayout, found:", err)
+		}
+	}
+
+	l, err := NewLayout(ts, Segment{Name: "a", Kind: SegmentCustom, Bits: 2}, Segment{Name: "b", Kind: SegmentCustom, Bits: 2}, seq)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if l.Bits() != 48 {
+		t.Error("expected 48 bits, found:", l.Bits())
+	}
+
+	segments := l.Segments()
+	if segments[0].Name != "timestamp" || segments[3].Name != "sequence" {
+		t.Error("segments without a name are not named after their kind:", segments)
+	}
+
+	segments[0].Bits = 1
+	if l.Segments()[0].Bits != 32 {
+		t.Error("Segments does not return a copy")
+	}
+}
+
+// TestNewConfigLayout tests ids of a config made by NewConfig decode back to their segments.
+func TestNewConfigLayout(t *testing.T) {
+	t.Parallel()
+
+	l, err := NewLayout(
+		Segment{Kind: SegmentTimestamp, Bits: 32},
+		Segment{Kind: SegmentDatacenter, Bits: 3},
+		Segment{Kind: SegmentServer, Bits: 8},
+		Segment{Kind: SegmentProcess, Bits: 4},
+		Segment{Kind: SegmentShard, Bits: 4},
+		Segment{Kind: SegmentSequence, Bits: 13},
+	)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	c, err := NewConfig[uint64](l, WithSegmentValue("datacenter", 5), WithSegmentValue("shard", 9))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if c.ServerBits != 8 || c.ProcessBits != 4 || c.SequenceBits != 13 {
+		t.Error("bits of the config do not match the layout:", c.ServerBits, c.ProcessBits, c.SequenceBits)
+	}
+
+	g, err := NewGenerator(&c, 200, 11)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	var prev uint64
+
+	for i := 0; i < 100; i++ {
+		id, err := g.Next()
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if id <= prev {
+			t.Fatal("id", id, "is not after", prev)
+		}
+
+		prev = id
+
+		parts := c.Decode(id)
+		if parts.DatacenterID != 5 || parts.ServerID != 200 || parts.ProcessID != 11 {
+			t.Fatal("decoded", parts, "does not match datacenterID: 5, serverID: 200, processID: 11")
+		}
+
+		if time.Since(parts.Time) > time.Minute {
+			t.Fatal("decoded time", parts.Time, "is not the current one")
+		}
+
+		want := map[string]uint64{
+			"datacenter": 5,
+			"server":     200,
+			"process":    11,
+			"shard":      9,
+			"sequence":   parts.Sequence,
+		}
+
+		if len(parts.Segments) != len(want) {
+			t.Fatal("expected segments", want, "found:", parts.Segments)
+		}
+
+		for name, v := range want {
+			if parts.Segments[name] != v {
+				t.Fatal("expected segments", want, "found:", parts.Segments)
+			}
+		}
+	}
+
+	// the timestamp takes the top 32 bits.
+	if ts := prev >> 32; ts != c.LastTime {
+		t.Error("expected timestamp", c.LastTime, "found:", ts)
+	}
+}
+
+// TestNewConfigLayoutUint32 tests a layout of uint32 ids without server and process segments.
+func TestNewConfigLayoutUint32(t *testing.T) {
+	t.Parallel()
+
+	l, err := NewLayout(
+		Segment{Kind: SegmentTimestamp, Bits: 16},
+		Segment{Kind: SegmentSequence, Bits: 10},
+		Segment{Name: "kind", Kind: SegmentCustom, Bits: 6},
+	)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	c, err := NewConfig[uint32](l, WithSegmentValue("kind", 42))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := c.Next(1, 0); !errors.Is(err, ErrServerIDOutOfRange) {
+		t.Error("expected ErrServerIDOutOfRange, found:", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		id, err := c.Next(0, 0)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if id&mask[uint32](6) != 42 {
+			t.Fatal("id", id, "does not end with the kind segment")
+		}
+
+		if parts := c.Decode(id); parts.Sequence != c.Sequence || parts.Segments["kind"] != 42 {
+			t.Fatal("decoded", parts, "does not match sequence", c.Sequence, "and kind 42")
+		}
+	}
+
+	wide, err := NewLayout(Segment{Kind: SegmentTimestamp, Bits: 16}, Segment{Kind: SegmentSequence, Bits: 17})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := NewConfig[uint32](wide); !errors.Is(err, ErrInvalidLayout) {
+		t.Error("expected ErrInvalidLayout for 33 bits, found:", err)
+	}
+
+	short, err := NewLayout(Segment{Kind: SegmentTimestamp, Bits: 15}, Segment{Kind: SegmentSequence, Bits: 17})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := NewConfig[uint32](short); !errors.Is(err, ErrInvalidLayout) {
+		t.Error("expected ErrInvalidLayout for 15 bits of timestamp, found:", err)
+	}
+
+	if _, err := NewConfig[uint32](nil); !errors.Is(err, ErrInvalidLayout) {
+		t.Error("expected ErrInvalidLayout for a nil layout, found:", err)
+	}
+}
+
+// TestWithSegmentValue tests values are only set on segments of the layout that fit them.
+func TestWithSegmentValue(t *testing.T) {
+	t.Parallel()
+
+	l, err := NewLayout(
+		Segment{Kind: SegmentTimestamp, Bits: 40},
+		Segment{Kind: SegmentServer, Bits: 8},
+		Segment{Kind: SegmentShard, Bits: 4},
+		Segment{Kind: SegmentSequence, Bits: 12},
+	)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := NewConfig[uint64](l, WithSegmentValue("shard", 16)); !errors.Is(err, ErrSegmentValueOutOfRange) {
+		t.Error("expected ErrSegmentValueOutOfRange, found:", err)
+	}
+
+	for _, name := range []string{"region", "server", "sequence", "timestamp"} {
+		if _, err := NewConfig[uint64](l, WithSegmentValue(name, 1)); !errors.Is(err, ErrInvalidLayout) {
+			t.Error(name, "expected ErrInvalidLayout, found:", err)
+		}
+	}
+
+	if _, err := NewUint64ConfigWithOptions(10, 5, 17, WithSegmentValue("shard", 1)); !errors.Is(err, ErrInvalidLayout) {
+		t.Error("expected ErrInvalidLayout without a layout, found:", err)
+	}
+}
+
+// TestShardedGeneratorLayout tests lanes stay in the sequence segment when it is not the last one.
+func TestShardedGeneratorLayout(t *testing.T) {
+	t.Parallel()
+
+	l, err := NewLayout(
+		Segment{Kind: SegmentTimestamp, Bits: 40},
+		Segment{Kind: SegmentSequence, Bits: 16},
+		Segment{Name: "tag", Kind: SegmentCustom, Bits: 8},
+	)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	c, err := NewConfig[uint64](l, WithLaneBits(2), WithSegmentValue("tag", 0xab))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewShardedGenerator(&c, 0, 0)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ids := map[uint64]struct{}{}
+
+	for i := 0; i < 1000; i++ {
+		id, err := g.Next()
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if _, ok := ids[id]; ok {
+			t.Fatal("duplicate id:", id)
+		}
+
+		ids[id] = struct{}{}
+
+		if tag := c.Decode(id).Segments["tag"]; tag != 0xab {
+			t.Fatal("id", id, "has tag", tag, "instead of 0xab")
+		}
+	}
+}
+
+// TestWithDatacenterID tests the datacenterID is validated against its segment and decoded back.
+func TestWithDatacenterID(t *testing.T) {
+	t.Parallel()
+
+	l, err := NewLayout(
+		Segment{Kind: SegmentTimestamp, Bits: 40},
+		Segment{Name: "region", Kind: SegmentDatacenter, Bits: 3},
+		Segment{Kind: SegmentServer, Bits: 9},
+		Segment{Kind: SegmentSequence, Bits: 12},
+	)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	c, err := NewConfig[uint64](l, WithDatacenterID(7))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if c.DatacenterID() != 7 {
+		t.Error("expected datacenterID 7, found:", c.DatacenterID())
+	}
+
+	id, err := c.Next(300, 0)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if parts := c.Decode(id); parts.DatacenterID != 7 || parts.Segments["region"] != 7 || parts.ServerID != 300 {
+		t.Error("decoded", parts, "does not match datacenterID: 7, serverID: 300")
+	}
+
+	// WithDatacenterID takes over the value set by name.
+	c, err = NewConfig[uint64](l, WithDatacenterID(7), WithSegmentValue("region", 2))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if c.DatacenterID() != 7 {
+		t.Error("expected datacenterID 7, found:", c.DatacenterID())
+	}
+
+	if _, err := NewConfig[uint64](l, WithDatacenterID(8)); !errors.Is(err, ErrDatacenterIDOutOfRange) {
+		t.Error("expected ErrDatacenterIDOutOfRange, found:", err)
+	}
+
+	if _, err := NewConfig[uint64](l, WithSegmentValue("region", 8)); !errors.Is(err, ErrDatacenterIDOutOfRange) {
+		t.Error("expected ErrDatacenterIDOutOfRange by name, found:", err)
+	}
+
+	noDatacenter, err := NewLayout(Segment{Kind: SegmentTimestamp, Bits: 40}, Segment{Kind: SegmentSequence, Bits: 12})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := NewConfig[uint64](noDatacenter, WithDatacenterID(1)); !errors.Is(err, ErrInvalidLayout) {
+		t.Error("expected ErrInvalidLayout without a datacenter segment, found:", err)
+	}
+
+	if _, err := NewUint64ConfigWithOptions(10, 5, 17, WithDatacenterID(1)); !errors.Is(err, ErrInvalidLayout) {
+		t.Error("expected ErrInvalidLayout without a layout, found:", err)
+	}
+
+	c64, err := NewUint64ConfigWithOptions(10, 5, 17)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if c64.DatacenterID() != 0 || c64.Decode(c64.pack(1, 2, 3, 4)).DatacenterID != 0 {
+		t.Error("a config without a layout has a datacenterID")
+	}
+}
+
+// TestWithEnvDatacenterID tests the datacenterID is read from DATACENTER_ID.
+func TestWithEnvDatacenterID(t *testing.T) {
+	l, err := NewLayout(
+		Segment{Kind: SegmentTimestamp, Bits: 40},
+		Segment{Kind: SegmentDatacenter, Bits: 4},
+		Segment{Kind: SegmentSequence, Bits: 12},
+	)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	t.Setenv(datacenterIDKey, "11")
+
+	c, err := NewConfig[uint64](l, WithEnvDatacenterID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if c.DatacenterID() != 11 {
+		t.Error("expected datacenterID 11, found:", c.DatacenterID())
+	}
+
+	t.Setenv(datacenterIDKey, "16")
+
+	if _, err := NewConfig[uint64](l, WithEnvDatacenterID()); !errors.Is(err, ErrDatacenterIDOutOfRange) {
+		t.Error("expected ErrDatacenterIDOutOfRange, found:", err)
+	}
+
+	t.Setenv(datacenterIDKey, "eu-west")
+
+	if _, err := NewConfig[uint64](l, WithEnvDatacenterID()); err == nil {
+		t.Error("expected an error parsing", os.Getenv(datacenterIDKey))
+	}
+
+	// a later WithDatacenterID takes over the environment.
+	if _, err := NewConfig[uint64](l, WithEnvDatacenterID(), WithDatacenterID(1)); err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
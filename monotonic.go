@@ -0,0 +1,79 @@
+package oneid
+
+import (
+	"sync"
+	"time"
+)
+
+// MonotonicClock is a Clock immune to wall-clock jumps such as NTP steps or VM migrations:
+// it reads the wall clock once and derives the current time from the monotonic clock since then,
+// so the time it returns never moves backwards.
+//
+// With a resync interval it reads the wall clock again once per interval,
+// moving forward to it when it is ahead and ignoring it otherwise.
+type MonotonicClock struct {
+	// wall reads the wall clock, mono reads the monotonic time elapsed since an arbitrary start.
+	wall func() time.Time
+	mono func() time.Duration
+
+	resync time.Duration
+
+	mu sync.Mutex
+
+	// anchor is the wall time at the monotonic reading anchorMono,
+	// synced is the monotonic reading of the latest resync.
+	anchor     time.Time
+	anchorMono time.Duration
+	synced     time.Duration
+}
+
+// NewMonotonicClock reads the wall clock and anchors the returned clock to it,
+// resync is the interval of reading the wall clock again, zero never does.
+func NewMonotonicClock(resync time.Duration) *MonotonicClock {
+	start := time.Now()
+
+	return newMonotonicClock(
+		func() time.Time { return time.Now().Round(0) },
+		func() time.Duration { return time.Since(start) },
+		resync,
+	)
+}
+
+// newMonotonicClock is NewMonotonicClock reading the wall and the monotonic time from wall and mono.
+func newMonotonicClock(wall func() time.Time, mono func() time.Duration, resync time.Duration) *MonotonicClock {
+	m := mono()
+
+	return &MonotonicClock{
+		wall:       wall,
+		mono:       mono,
+		resync:     resync,
+		anchor:     wall(),
+		anchorMono: m,
+		synced:     m,
+	}
+}
+
+// Now returns the anchored wall time plus the monotonic time elapsed since the anchor.
+func (c *MonotonicClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := c.mono()
+	now := c.anchor.Add(m - c.anchorMono)
+
+	if c.resync > 0 && m-c.synced >= c.resync {
+		c.synced = m
+
+		if wall := c.wall(); wall.After(now) {
+			c.anchor, c.anchorMono = wall, m
+			now = wall
+		}
+	}
+
+	return now
+}
+
+// After returns time.After(d), timers already run on the monotonic clock.
+func (c *MonotonicClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -0,0 +1,79 @@
+package oneid
+
+import (
+	"testing"
+	"time"
+)
+
+// TestMonotonicClock tests MonotonicClock ignores wall-clock jumps and only resyncs forward.
+func TestMonotonicClock(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	var (
+		wall    = start
+		elapsed time.Duration
+	)
+
+	c := newMonotonicClock(
+		func() time.Time { return wall },
+		func() time.Duration { return elapsed },
+		time.Minute,
+	)
+
+	elapsed += 10 * time.Second
+	wall = wall.Add(-time.Hour)
+
+	if now := c.Now(); !now.Equal(start.Add(10 * time.Second)) {
+		t.Error("expected the wall-clock step back to be ignored, found:", now)
+	}
+
+	// a resync to a wall clock behind the monotonic time does not move backwards.
+	elapsed += time.Minute
+
+	if now := c.Now(); !now.Equal(start.Add(70 * time.Second)) {
+		t.Error("expected no regression on resync, found:", now)
+	}
+
+	// the wall clock ahead is adopted, yet only once the resync interval passes.
+	wall = start.Add(time.Hour)
+	elapsed += time.Second
+
+	if now := c.Now(); !now.Equal(start.Add(71 * time.Second)) {
+		t.Error("expected no resync before the interval, found:", now)
+	}
+
+	elapsed += time.Minute
+
+	if now := c.Now(); !now.Equal(start.Add(time.Hour)) {
+		t.Error("expected a forward resync to", start.Add(time.Hour), "found:", now)
+	}
+
+	elapsed += time.Second
+
+	if now := c.Now(); !now.Equal(start.Add(time.Hour + time.Second)) {
+		t.Error("expected to count from the new anchor, found:", now)
+	}
+}
+
+// TestMonotonicClockConfig tests configs generate ids with a MonotonicClock.
+func TestMonotonicClockConfig(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 17, WithClock(NewMonotonicClock(time.Minute)))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	before := time.Now().Truncate(time.Second)
+
+	id, err := c.Next(1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if parts := c.Decode(id); parts.Time.Before(before) || parts.Time.After(time.Now()) {
+		t.Error("decoded time", parts.Time, "is not within", before, "and now")
+	}
+}
@@ -0,0 +1,101 @@
+// Package oneidtest provides helpers to test code generating ids with oneid,
+// such as a manual clock to pass to oneid.WithClock.
+package oneidtest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a manual clock satisfying oneid.Clock, its time only changes by Advance and Set,
+// so tests can deterministically cover ticks, wraparound, rollback and drift.
+//
+// It is cocurrently-safe, goroutines waiting on After are released once the clock reaches their deadline.
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+}
+
+// waiter is a pending After call.
+type waiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewClock returns a Clock reading now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the current time of c.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// After returns a channel receiving the time once c is advanced by d,
+// it receives right away when d is not positive.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+
+	if d <= 0 {
+		ch <- c.now
+
+		return ch
+	}
+
+	c.waiters = append(c.waiters, waiter{deadline: c.now.Add(d), ch: ch})
+
+	return ch
+}
+
+// Advance moves c forward by d, releasing the waiters whose deadline is reached.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(c.now.Add(d))
+}
+
+// Set moves c to now, backwards included to simulate a clock regression,
+// releasing the waiters whose deadline is reached.
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(now)
+}
+
+// Waiters returns the number of pending After calls,
+// tests can poll it to know a goroutine is waiting before advancing c.
+func (c *Clock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.waiters)
+}
+
+// set moves c to now under its lock.
+func (c *Clock) set(now time.Time) {
+	c.now = now
+
+	pending := c.waiters[:0]
+
+	for _, w := range c.waiters {
+		if now.Before(w.deadline) {
+			pending = append(pending, w)
+
+			continue
+		}
+
+		w.ch <- now
+	}
+
+	c.waiters = pending
+}
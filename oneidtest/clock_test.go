@@ -0,0 +1,59 @@
+package oneidtest
+
+import (
+	"testing"
+	"time"
+)
+
+// TestClock tests Clock only moves by Advance and Set and releases its waiters on time.
+func TestClock(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewClock(start)
+
+	if !c.Now().Equal(start) {
+		t.Error("expected", start, "found:", c.Now())
+	}
+
+	select {
+	case <-c.After(0):
+	default:
+		t.Error("expected After(0) to receive right away")
+	}
+
+	ch := c.After(time.Second)
+
+	if c.Waiters() != 1 {
+		t.Error("expected 1 waiter, found:", c.Waiters())
+	}
+
+	c.Advance(999 * time.Millisecond)
+
+	select {
+	case <-ch:
+		t.Error("After(1s) received before a second passed")
+	default:
+	}
+
+	c.Advance(time.Millisecond)
+
+	select {
+	case now := <-ch:
+		if !now.Equal(start.Add(time.Second)) {
+			t.Error("expected", start.Add(time.Second), "found:", now)
+		}
+	default:
+		t.Error("expected After(1s) to receive once a second passed")
+	}
+
+	if c.Waiters() != 0 {
+		t.Error("expected no waiters, found:", c.Waiters())
+	}
+
+	c.Set(start)
+
+	if !c.Now().Equal(start) {
+		t.Error("expected Set to move the clock backwards to", start, "found:", c.Now())
+	}
+}
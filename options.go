@@ -0,0 +1,246 @@
+package oneid
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Option customizes the configuration made by NewUint32ConfigWithOptions
+// and NewUint64ConfigWithOptions.
+type Option func(*options)
+
+// options holds the settings collected from Option values.
+type options struct {
+	epoch    time.Time
+	tick     time.Duration
+	rollback RollbackPolicy
+	maxDrift time.Duration
+	lanes    uint
+
+	stateFile       string
+	persistInterval time.Duration
+
+	startupGuard bool
+	guardWindow  time.Duration
+
+	clock Clock
+
+	values map[string]uint64
+
+	datacenterID  uint64
+	datacenterSet bool
+	datacenterEnv bool
+
+	types []typeTag
+}
+
+// WithEpoch sets the epoch generated timestamps are relative to,
+// a recent epoch such as the launch date of the service makes the timestamp bits last longer.
+//
+// The epoch is truncated to whole seconds and cannot be in the future.
+func WithEpoch(epoch time.Time) Option {
+	return func(o *options) {
+		o.epoch = epoch
+	}
+}
+
+// WithTick sets the resolution of the timestamp bits, both for generating and decoding ids.
+// Fine ticks such as time.Millisecond suit high-throughput services,
+// coarse ticks such as time.Minute make the timestamp bits last longer.
+//
+// Uint64Config ticks every second and Uint32Config every hour by default.
+func WithTick(d time.Duration) Option {
+	return func(o *options) {
+		o.tick = d
+	}
+}
+
+// WithRollbackPolicy sets what the config does when the clock reads behind a time it has already read,
+// RollbackBorrow is used by default.
+func WithRollbackPolicy(p RollbackPolicy) Option {
+	return func(o *options) {
+		o.rollback = p
+	}
+}
+
+// WithMaxDrift bounds how far the timestamp of ids may run ahead of the clock
+// when the sequence of a tick is exhausted and the following ticks are borrowed.
+// Once the bound is reached Uint32, Uint64 and Generator.All wait for the clock to catch up,
+// while Generator.Reserve and Generator.NextN return ErrDriftExceeded.
+//
+// Borrowing is unbounded by default, Config.Drift reports the current drift.
+func WithMaxDrift(d time.Duration) Option {
+	return func(o *options) {
+		o.maxDrift = d
+	}
+}
+
+// WithLaneBits reserves the top n bits of the sequence for the lanes of a ShardedGenerator,
+// it splits the sequence of every tick into 2^n lanes generating concurrently.
+//
+// No bits are reserved by default, n must be less than sequenceBits.
+func WithLaneBits(n uint) Option {
+	return func(o *options) {
+		o.lanes = n
+	}
+}
+
+// WithStateFile persists the high-water mark of the ids to path, so after a restart,
+// a clock regression included, no id is issued below the ones of the previous run.
+//
+// The mark is written an interval ahead of the latest id, see WithPersistInterval,
+// the file is fsynced and replaced by an atomic rename. On startup the config resumes
+// from the mark, borrowing, blocking or erroring per RollbackPolicy until the clock passes it.
+func WithStateFile(path string) Option {
+	return func(o *options) {
+		o.stateFile = path
+	}
+}
+
+// WithPersistInterval sets how far ahead of the latest id the state file is written,
+// thus how often it is written. A longer interval writes less often,
+// yet a restart resumes further ahead of the clock.
+//
+// It defaults to 5 seconds and has no effect without WithStateFile.
+func WithPersistInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.persistInterval = d
+	}
+}
+
+// WithStartupGuard makes the constructor wait until the clock moves past the tick it started in
+// and at least window passes, so a process restarted with the same serverID and processID
+// cannot overlap the ids of its crashed predecessor without persisting anything, see WithStateFile.
+//
+// A window of MaxDrift also covers the ticks the predecessor may have borrowed.
+// Mind the wait is up to a whole tick, an hour for Uint32Config by default.
+func WithStartupGuard(window time.Duration) Option {
+	return func(o *options) {
+		o.startupGuard = true
+		o.guardWindow = window
+	}
+}
+
+// WithClock replaces the system clock the config reads and waits on,
+// e.g. with a manual clock of the oneidtest package to test time-based behavior.
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithSegmentValue sets the value of the segment called name in every id of the config,
+// e.g. a shard or a custom segment of a layout made by NewLayout.
+//
+// The timestamp, server, process and sequence segments are not set by value.
+func WithSegmentValue(name string, v uint64) Option {
+	return func(o *options) {
+		if o.values == nil {
+			o.values = map[string]uint64{}
+		}
+
+		o.values[name] = v
+	}
+}
+
+// WithDatacenterID sets the datacenterID every id of the config carries in its datacenter segment,
+// the layout made by NewLayout must have a SegmentDatacenter. It takes over WithSegmentValue for that segment.
+func WithDatacenterID(id uint64) Option {
+	return func(o *options) {
+		o.datacenterID = id
+		o.datacenterSet = true
+		o.datacenterEnv = false
+	}
+}
+
+// WithEnvDatacenterID is like WithDatacenterID, besides it reads the datacenterID
+// from the environment variable DATACENTER_ID.
+func WithEnvDatacenterID() Option {
+	return func(o *options) {
+		o.datacenterEnv = true
+	}
+}
+
+// WithType registers name for tag in the type segment of the layout made by NewLayout,
+// see NewTypedGenerator, TypeOf and CheckType. Tags and names must be unique.
+func WithType(tag uint64, name string) Option {
+	return func(o *options) {
+		o.types = append(o.types, typeTag{tag: tag, name: name})
+	}
+}
+
+// newOptions applies opts over the defaults and validates the result.
+func newOptions(opts []Option) (options, error) {
+	o := options{
+		epoch: time.Unix(defaultEpoch, 0),
+		clock: SystemClock{},
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.datacenterEnv {
+		id, err := strconv.ParseUint(os.Getenv(datacenterIDKey), 10, 64)
+		if err != nil {
+			return o, fmt.Errorf("parsing datacenterID from env("+datacenterIDKey+") -> %w", err)
+		}
+
+		o.datacenterID = id
+		o.datacenterSet = true
+	}
+
+	if o.clock == nil {
+		return o, fmt.Errorf("clock cannot be nil")
+	}
+
+	if o.epoch.After(o.clock.Now()) {
+		return o, fmt.Errorf("%w: %s", ErrEpochInFuture, o.epoch)
+	}
+
+	if o.epoch.Unix() < 0 {
+		return o, fmt.Errorf("%w: %s is before the unix epoch", ErrInvalidEpoch, o.epoch)
+	}
+
+	if o.tick < 0 {
+		return o, fmt.Errorf("tick duration cannot be negative: %s", o.tick)
+	}
+
+	if o.maxDrift < 0 {
+		return o, fmt.Errorf("max drift cannot be negative: %s", o.maxDrift)
+	}
+
+	if o.persistInterval < 0 {
+		return o, fmt.Errorf("persist interval cannot be negative: %s", o.persistInterval)
+	}
+
+	if o.guardWindow < 0 {
+		return o, fmt.Errorf("startup guard window cannot be negative: %s", o.guardWindow)
+	}
+
+	if o.rollback > RollbackError {
+		return o, fmt.Errorf("unknown rollback policy: %s", o.rollback)
+	}
+
+	return o, nil
+}
+
+// tickOr returns the tick of o, or d when no tick is set.
+func (o options) tickOr(d time.Duration) time.Duration {
+	if o.tick == 0 {
+		return d
+	}
+
+	return o.tick
+}
+
+// persistIntervalOr returns the persist interval of o, or d when none is set.
+func (o options) persistIntervalOr(d time.Duration) time.Duration {
+	if o.persistInterval == 0 {
+		return d
+	}
+
+	return o.persistInterval
+}
@@ -0,0 +1,34 @@
+package oneid
+
+import "strconv"
+
+// RollbackPolicy decides what a config does when the clock reads behind
+// a time it has already read, e.g. after an NTP step backwards.
+// Ids running ahead of the clock because they borrowed the following ticks are bounded by MaxDrift instead.
+type RollbackPolicy uint8
+
+const (
+	// RollbackBorrow keeps issuing ids from the latest timestamp until the clock catches up,
+	// it is the default policy.
+	RollbackBorrow RollbackPolicy = iota
+
+	// RollbackBlock waits until the clock catches up with its latest reading.
+	RollbackBlock
+
+	// RollbackError fails with ErrClockMovedBackwards until the clock catches up with its latest reading.
+	RollbackError
+)
+
+// String returns the name of p.
+func (p RollbackPolicy) String() string {
+	switch p {
+	case RollbackBorrow:
+		return "borrow"
+	case RollbackBlock:
+		return "block"
+	case RollbackError:
+		return "error"
+	}
+
+	return "RollbackPolicy(" + strconv.Itoa(int(p)) + ")"
+}
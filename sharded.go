@@ -0,0 +1,104 @@
+package oneid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ShardedGenerator spreads id generation over 2^LaneBits lanes with a mutex each,
+// so goroutines running on different Ps rarely wait on the same lock.
+//
+// The top LaneBits of the sequence hold the lane and the rest count the ids of the lane,
+// ids stay unique and decode as usual, yet they are only ordered by time across lanes,
+// unlike Generator they are not strictly increasing.
+// Like AtomicGenerator it keeps its own state seeded from the config at construction.
+type ShardedGenerator[T ID] struct {
+	config *Config[T]
+	lanes  []Config[T]
+	pool   sync.Pool
+	turn   uint32
+
+	// bases holds the id of each lane without timestamp and sequence.
+	bases []T
+}
+
+// NewShardedGenerator binds serverID and processID to c like NewGenerator,
+// c must reserve lane bits, see WithLaneBits.
+func NewShardedGenerator[T ID](c *Config[T], serverID, processID T) (*ShardedGenerator[T], error) {
+	if err := c.checkIDs(serverID, processID); err != nil {
+		return nil, err
+	}
+
+	if c.LaneBits == 0 || c.LaneBits >= c.SequenceBits {
+		return nil, fmt.Errorf("%w: laneBits(%d) must be between 1 and sequenceBits(%d) - 1",
+			ErrInvalidLayout, c.LaneBits, c.SequenceBits)
+	}
+
+	g := &ShardedGenerator[T]{
+		config: c,
+		lanes:  make([]Config[T], 1<<c.LaneBits),
+		bases:  make([]T, 1<<c.LaneBits),
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	// the lane is the top of the sequence, so a lane packs its ids with the lane in its base
+	// and keeps the layout of c, counting only the sequence below the lane.
+	laneBits := c.SequenceBits - c.LaneBits
+	laneOffset := c.offset(SegmentSequence) + laneBits
+
+	for i := range g.lanes {
+		lane := *c
+		lane.laneMask = mask(laneBits)
+		lane.Sequence = 0
+		lane.Mutex = &sync.Mutex{}
+
+		// a lane carries the sequence of c forward within its part of the sequence,
+		// so the ids c issued in its latest tick are not issued again.
+		if start := T(i) << laneBits; c.Sequence >= start {
+			lane.Sequence = min(c.Sequence-start, mask(laneBits))
+		}
+
+		g.lanes[i] = lane
+		g.bases[i] = c.base(serverID, processID) | T(i)<<laneOffset
+	}
+
+	g.pool.New = func() any {
+		return T(atomic.AddUint32(&g.turn, 1)) & mask(c.LaneBits)
+	}
+
+	return g, nil
+}
+
+// Next generates an id from the lane cached for the current P, it returns the same errors as Config.Next.
+// Once the sequence of its lane is exhausted the other lanes are tried in turn,
+// so ErrSequenceExhausted is only returned when every lane used up its part of the tick.
+func (g *ShardedGenerator[T]) Next() (T, error) {
+	lane := g.pool.Get().(T)
+	defer g.pool.Put(lane)
+
+	var (
+		id  T
+		err error
+	)
+
+	for i := range len(g.lanes) {
+		l := (int(lane) + i) % len(g.lanes)
+
+		id, err = g.lanes[l].next(context.Background(), g.bases[l], false)
+		if !errors.Is(err, ErrSequenceExhausted) {
+			return id, err
+		}
+	}
+
+	return 0, err
+}
+
+// Config returns the config g generates ids from, e.g. to Decode them.
+func (g *ShardedGenerator[T]) Config() *Config[T] {
+	return g.config
+}
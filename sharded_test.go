@@ -0,0 +1,182 @@
+package oneid
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coderme/oneid/v3/oneidtest"
+)
+
+var _ IDGenerator[uint64] = (*ShardedGenerator[uint64])(nil)
+
+// TestNewShardedGenerator tests NewShardedGenerator requires lane bits.
+func TestNewShardedGenerator(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 17)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := NewShardedGenerator(&c, 1, 1); !errors.Is(err, ErrInvalidLayout) {
+		t.Error("expected ErrInvalidLayout, found:", err)
+	}
+
+	if _, err := NewUint64ConfigWithOptions(10, 5, 17, WithLaneBits(17)); !errors.Is(err, ErrInvalidLayout) {
+		t.Error("expected ErrInvalidLayout, found:", err)
+	}
+
+	if _, err := NewUint32ConfigWithOptions(4, 2, 10, WithLaneBits(40)); !errors.Is(err, ErrInvalidLayout) {
+		t.Error("expected ErrInvalidLayout, found:", err)
+	}
+}
+
+// TestShardedGeneratorNext tests ShardedGenerator never repeats an id across goroutines.
+func TestShardedGeneratorNext(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 17, WithLaneBits(3))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewShardedGenerator(&c, 700, 21)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	var wg sync.WaitGroup
+
+	ids := newIDChecker(t, &c, 700, 21)
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 500; i++ {
+				id, err := g.Next()
+				if err != nil {
+					t.Error("unexpected error:", err)
+
+					return
+				}
+
+				if !ids.check(id) {
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// TestShardedGeneratorSeeded tests lanes do not reissue the ids the config issued in the same tick.
+func TestShardedGeneratorSeeded(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 6, WithLaneBits(2), WithClock(oneidtest.NewClock(time.Now())))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	gen, err := NewGenerator(&c, 1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ids := map[uint64]struct{}{}
+
+	// the frozen clock keeps every id in one tick, 20 ids use up lane 0 and part of lane 1.
+	for i := 0; i < 20; i++ {
+		id, err := gen.Next()
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		ids[id] = struct{}{}
+	}
+
+	g, err := NewShardedGenerator(&c, 1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for i := range g.lanes {
+		for {
+			id, err := g.lanes[i].next(context.Background(), g.bases[i], false)
+			if errors.Is(err, ErrSequenceExhausted) {
+				break
+			}
+
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if _, ok := ids[id]; ok {
+				t.Fatal("lane", i, "reissued id:", id)
+			}
+
+			ids[id] = struct{}{}
+		}
+	}
+
+	// lane 1 issues the sequences 20 to 31, lanes 2 and 3 all but the first of theirs.
+	if len(ids) != 20+12+15+15 {
+		t.Error("expected", 20+12+15+15, "ids, found:", len(ids))
+	}
+}
+
+// TestShardedGeneratorExhaustedLane tests Next moves on to the other lanes once its own is exhausted.
+func TestShardedGeneratorExhaustedLane(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 12, WithLaneBits(2), WithClock(oneidtest.NewClock(time.Now())))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewShardedGenerator(&c, 700, 21)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ids := newIDChecker(t, &c, 700, 21)
+
+	// the frozen clock keeps every id in one tick, each of the 4 lanes holds 1024 of them.
+	for i := 0; i < 1<<c.SequenceBits; i++ {
+		id, err := g.Next()
+		if err != nil {
+			t.Fatal("unexpected error at id", i, "error:", err)
+		}
+
+		if !ids.check(id) {
+			t.FailNow()
+		}
+	}
+
+	if _, err := g.Next(); !errors.Is(err, ErrSequenceExhausted) {
+		t.Error("expected ErrSequenceExhausted, found:", err)
+	}
+}
+
+// BenchmarkShardedGeneratorParallel benchmarks ShardedGenerator under contention.
+func BenchmarkShardedGeneratorParallel(b *testing.B) {
+	c := mustUint64Config(NewUint64ConfigWithOptions(1, 1, 30, WithLaneBits(4)))
+
+	g, err := NewShardedGenerator(&c, 1, 1)
+	if err != nil {
+		b.Fatal("unexpected error:", err)
+	}
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = g.Next()
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package oneid
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultPersistInterval is how far ahead the high-water mark is persisted unless WithPersistInterval is used.
+const defaultPersistInterval = 5 * time.Second
+
+// stateFile persists a high-water mark, a time no id has been issued at or after,
+// so a restarted process never issues ids below the ones of its previous run.
+//
+// The mark is kept an interval ahead of the latest id, thus it is only written once per interval.
+type stateFile struct {
+	path     string
+	interval time.Duration
+
+	mu   sync.Mutex
+	mark time.Time
+}
+
+// openStateFile reads the high-water mark persisted at path, a missing file means no mark.
+func openStateFile(path string, interval time.Duration) (*stateFile, error) {
+	s := &stateFile{
+		path:     path,
+		interval: interval,
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading state file %s -> %w", path, err)
+	}
+
+	nanos, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parsing state file %s -> %w", path, err)
+	}
+
+	s.mark = time.Unix(0, nanos)
+
+	return s, nil
+}
+
+// ensure persists next as the high-water mark unless ids at t are already below the current one.
+func (s *stateFile) ensure(t, next time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.Before(s.mark) {
+		return nil
+	}
+
+	if err := s.write(next); err != nil {
+		return fmt.Errorf("persisting state file %s -> %w", s.path, err)
+	}
+
+	s.mark = next
+
+	return nil
+}
+
+// write replaces the file with mark atomically: it writes and fsyncs a temporary file,
+// renames it over the state file, then fsyncs the directory so the rename is durable.
+func (s *stateFile) write(mark time.Time) error {
+	tmp := s.path + ".tmp"
+
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString(strconv.FormatInt(mark.UnixNano(), 10) + "\n"); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+
+	dir, err := os.Open(filepath.Dir(s.path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	return dir.Sync()
+}
+
+// restoreState sets LastTime to the first tick at or after the persisted high-water mark,
+// so no id below the ones of the previous run is issued.
+//
+// The mark is a lower bound for the ids rather than a clock reading: while the clock is behind it
+// ids are issued from the sequence of its tick, and RollbackPolicy only applies once the clock
+// reads behind a time it has already read.
+func (c *Config[T]) restoreState() {
+	if c.state == nil || c.state.mark.IsZero() {
+		return
+	}
+
+	elapsed := c.state.mark.Sub(c.epoch())
+	if elapsed <= 0 {
+		return
+	}
+
+	// round up to the tick holding the mark, unless the mark starts a tick.
+	mark := T((elapsed + c.tick() - 1) / c.tick())
+	if mark > c.LastTime {
+		c.LastTime = mark
+		c.Sequence = 0
+	}
+}
+
+// persist keeps the high-water mark of the state file ahead of the ids of tick t,
+// it is a no-op without a state file or when the mark is already ahead.
+func (c *Config[T]) persist(t T) error {
+	if c.state == nil {
+		return nil
+	}
+
+	return c.state.ensure(c.tickTime(t), c.tickTime(t+1).Add(c.state.interval))
+}
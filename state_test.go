@@ -0,0 +1,140 @@
+package oneid
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/coderme/oneid/v3/oneidtest"
+)
+
+// TestStateFileRestart tests a config resumes above the ids issued before a restart.
+func TestStateFileRestart(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "oneid.state")
+
+	before, err := NewUint64ConfigWithOptions(10, 5, 17, WithStateFile(path), WithPersistInterval(time.Minute))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewGenerator(&before, 1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	last, err := g.Next()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal("expected the state file to be written, found:", err)
+	}
+
+	if _, err := os.Stat(path + ".tmp"); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("expected the temporary file to be renamed, found:", err)
+	}
+
+	after, err := NewUint64ConfigWithOptions(10, 5, 17, WithStateFile(path), WithPersistInterval(time.Minute))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if after.LastTime <= before.LastTime+60 {
+		t.Error("expected to resume a minute ahead of", before.LastTime, "found:", after.LastTime)
+	}
+
+	g, err = NewGenerator(&after, 1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	id, err := g.Next()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if id <= last {
+		t.Error("id", id, "after the restart is not above", last)
+	}
+
+	// the clock is behind the mark without ever reading a later time, so it did not move backwards.
+	for _, p := range []RollbackPolicy{RollbackBlock, RollbackError} {
+		start := time.Now()
+		clk := oneidtest.NewClock(start)
+
+		strict, err := NewUint64ConfigWithOptions(10, 5, 17, WithStateFile(path), WithRollbackPolicy(p), WithClock(clk))
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		done := make(chan error, 1)
+
+		go func() {
+			id, err := strict.Next(1, 1)
+			if err == nil && id <= last {
+				err = fmt.Errorf("id %d after the restart is not above %d", id, last)
+			}
+
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Error(p, "unexpected error:", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal(p, "is waiting for the clock to reach the mark")
+		}
+
+		if p != RollbackError {
+			continue
+		}
+
+		clk.Set(start.Add(-time.Minute))
+
+		if _, err := strict.Next(1, 1); !errors.Is(err, ErrClockMovedBackwards) {
+			t.Error("expected ErrClockMovedBackwards, found:", err)
+		}
+	}
+}
+
+// TestStateFileErrors tests unreadable and unwritable state files are reported.
+func TestStateFileErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	corrupt := filepath.Join(dir, "corrupt.state")
+	if err := os.WriteFile(corrupt, []byte("not a time\n"), 0o644); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := NewUint64ConfigWithOptions(10, 5, 17, WithStateFile(corrupt)); err == nil {
+		t.Error("expected an error for a corrupt state file")
+	}
+
+	if _, err := NewUint64ConfigWithOptions(10, 5, 17, WithPersistInterval(-time.Second)); err == nil {
+		t.Error("expected an error for a negative persist interval")
+	}
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 17, WithStateFile(filepath.Join(dir, "missing", "oneid.state")))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := c.Next(1, 1); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("expected fs.ErrNotExist, found:", err)
+	}
+
+	if id := Uint64(1, 1, &c); id != 0 {
+		t.Error("expected a zero id when the state file cannot be written, found:", id)
+	}
+}
@@ -0,0 +1,124 @@
+package oneid
+
+import "fmt"
+
+// typeTag is a tag of the type segment and the name registered for it by WithType.
+type typeTag struct {
+	tag  uint64
+	name string
+}
+
+// typeRegistry maps the tags of the type segment to their names and back.
+type typeRegistry[T ID] struct {
+	names map[T]string
+	tags  map[string]T
+}
+
+// setTypes registers types, the layout of c must have a type segment holding every tag
+// and neither a tag nor a name may be registered twice.
+func (c *Config[T]) setTypes(types []typeTag) error {
+	if len(types) == 0 {
+		return nil
+	}
+
+	var s Segment
+
+	if c.Layout != nil {
+		s, _, _ = c.Layout.kind(SegmentType)
+	}
+
+	if s.Bits == 0 {
+		return fmt.Errorf("%w: no type segment, see NewLayout", ErrInvalidLayout)
+	}
+
+	c.types = typeRegistry[T]{
+		names: make(map[T]string, len(types)),
+		tags:  make(map[string]T, len(types)),
+	}
+
+	for _, t := range types {
+		if t.tag > uint64(mask(T(s.Bits))) {
+			return fmt.Errorf("%w: tag %d of type %q does not fit in %d bits",
+				ErrSegmentValueOutOfRange, t.tag, t.name, s.Bits)
+		}
+
+		tag := T(t.tag)
+
+		switch {
+		case t.name == "":
+			return fmt.Errorf("type of tag %d cannot have an empty name", t.tag)
+		case c.types.names[tag] != "":
+			return fmt.Errorf("tag %d is registered for both %q and %q", t.tag, c.types.names[tag], t.name)
+		}
+
+		if _, ok := c.types.tags[t.name]; ok {
+			return fmt.Errorf("type %q is registered for both tags %d and %d", t.name, c.types.tags[t.name], t.tag)
+		}
+
+		c.types.names[tag] = t.name
+		c.types.tags[t.name] = tag
+	}
+
+	return nil
+}
+
+// typeBase returns base with its type segment holding the tag of the type called name.
+func (c *Config[T]) typeBase(base T, name string) (T, error) {
+	tag, ok := c.types.tags[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownType, name)
+	}
+
+	s, offset, _ := c.Layout.kind(SegmentType)
+
+	return base&^(mask(T(s.Bits))<<offset) | tag<<offset, nil
+}
+
+// TypeOf returns the name of the type id refers to, as registered by WithType.
+// ErrUnknownType is returned when its tag is not registered.
+func (c *Config[T]) TypeOf(id T) (string, error) {
+	tag := c.segment(SegmentType, id)
+
+	name, ok := c.types.names[tag]
+	if !ok {
+		return "", fmt.Errorf("%w: tag %d of id %d", ErrUnknownType, tag, id)
+	}
+
+	return name, nil
+}
+
+// CheckType returns ErrTypeMismatch unless id refers to the type called name,
+// e.g. to reject the id of a user where the id of an order is expected.
+// ErrUnknownType is returned when name is not registered.
+func (c *Config[T]) CheckType(id T, name string) error {
+	want, ok := c.types.tags[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownType, name)
+	}
+
+	if tag := c.segment(SegmentType, id); tag != want {
+		if found, ok := c.types.names[tag]; ok {
+			return fmt.Errorf("%w: id %d is %q, not %q", ErrTypeMismatch, id, found, name)
+		}
+
+		return fmt.Errorf("%w: id %d has tag %d, not the one of %q", ErrTypeMismatch, id, tag, name)
+	}
+
+	return nil
+}
+
+// NewTypedGenerator is like NewGenerator, besides its ids carry the tag of the type called name
+// in their type segment, it returns ErrUnknownType unless name is registered by WithType.
+// Generators of different types may share c.
+func NewTypedGenerator[T ID](c *Config[T], name string, serverID, processID T) (*Generator[T], error) {
+	g, err := NewGenerator(c, serverID, processID)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.base, err = c.typeBase(g.base, name); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
@@ -0,0 +1,162 @@
+package oneid
+
+import (
+	"errors"
+	"testing"
+)
+
+// typesLayout has 4 bits of type tags between the server and the sequence.
+func typesLayout(t *testing.T) *Layout {
+	t.Helper()
+
+	l, err := NewLayout(
+		Segment{Kind: SegmentTimestamp, Bits: 40},
+		Segment{Kind: SegmentServer, Bits: 8},
+		Segment{Name: "entity", Kind: SegmentType, Bits: 4},
+		Segment{Kind: SegmentSequence, Bits: 12},
+	)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return l
+}
+
+// TestWithType tests the registry rejects tags that do not fit and repeated tags or names.
+func TestWithType(t *testing.T) {
+	t.Parallel()
+
+	l := typesLayout(t)
+
+	if _, err := NewConfig[uint64](l, WithType(16, "user")); !errors.Is(err, ErrSegmentValueOutOfRange) {
+		t.Error("expected ErrSegmentValueOutOfRange, found:", err)
+	}
+
+	for name, opts := range map[string][]Option{
+		"empty name":    {WithType(1, "")},
+		"repeated tag":  {WithType(1, "user"), WithType(1, "order")},
+		"repeated name": {WithType(1, "user"), WithType(2, "user")},
+	} {
+		if _, err := NewConfig[uint64](l, opts...); err == nil {
+			t.Error(name, "expected an error")
+		}
+	}
+
+	if _, err := NewUint64ConfigWithOptions(10, 5, 17, WithType(1, "user")); !errors.Is(err, ErrInvalidLayout) {
+		t.Error("expected ErrInvalidLayout without a type segment, found:", err)
+	}
+
+	c, err := NewConfig[uint64](l)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := NewTypedGenerator(&c, "user", 1, 0); !errors.Is(err, ErrUnknownType) {
+		t.Error("expected ErrUnknownType, found:", err)
+	}
+}
+
+// TestTypedGenerator tests ids of typed generators sharing a config tell their type back.
+func TestTypedGenerator(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConfig[uint64](typesLayout(t), WithType(1, "user"), WithType(2, "order"), WithType(15, "invoice"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := NewTypedGenerator(&c, "order", 256, 0); !errors.Is(err, ErrServerIDOutOfRange) {
+		t.Error("expected ErrServerIDOutOfRange, found:", err)
+	}
+
+	var prev uint64
+
+	for _, name := range []string{"user", "order", "invoice"} {
+		g, err := NewTypedGenerator(&c, name, 200, 0)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		for i := 0; i < 100; i++ {
+			id, err := g.Next()
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if id <= prev {
+				t.Fatal("id", id, "is not after", prev)
+			}
+
+			prev = id
+
+			if found, err := c.TypeOf(id); err != nil || found != name {
+				t.Fatal("expected type", name, "found:", found, err)
+			}
+
+			if err := c.CheckType(id, name); err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if parts := c.Decode(id); parts.Type != name || parts.ServerID != 200 {
+				t.Fatal("decoded", parts, "does not match type", name, "and serverID 200")
+			}
+		}
+	}
+
+	invoice := prev
+
+	if err := c.CheckType(invoice, "user"); !errors.Is(err, ErrTypeMismatch) {
+		t.Error("expected ErrTypeMismatch, found:", err)
+	}
+
+	if err := c.CheckType(invoice, "payment"); !errors.Is(err, ErrUnknownType) {
+		t.Error("expected ErrUnknownType for an unregistered name, found:", err)
+	}
+
+	// ids of a generator without a type carry tag 0, which is not registered.
+	g, err := NewGenerator(&c, 200, 0)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	untyped, err := g.Next()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := c.TypeOf(untyped); !errors.Is(err, ErrUnknownType) {
+		t.Error("expected ErrUnknownType, found:", err)
+	}
+
+	if err := c.CheckType(untyped, "user"); !errors.Is(err, ErrTypeMismatch) {
+		t.Error("expected ErrTypeMismatch, found:", err)
+	}
+
+	if parts := c.Decode(untyped); parts.Type != "" {
+		t.Error("expected no type, found:", parts.Type)
+	}
+}
+
+// TestTypedGeneratorSegmentValue tests the tag of a typed generator takes over the one set by WithSegmentValue.
+func TestTypedGeneratorSegmentValue(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConfig[uint64](typesLayout(t), WithType(1, "user"), WithType(2, "order"), WithSegmentValue("entity", 3))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	g, err := NewTypedGenerator(&c, "user", 1, 0)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	id, err := g.Next()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := c.CheckType(id, "user"); err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
@@ -1,6 +1,7 @@
 package oneid
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strconv"
@@ -9,33 +10,39 @@ import (
 )
 
 const (
-	defaultUint32ProcessBits  uint32 = 5
-	defaultUint32ServerBits   uint32 = 10
-	defaultUint32SequenceBits uint32 = 20
+	defaultUint32ProcessBits  uint32 = 2
+	defaultUint32ServerBits   uint32 = 4
+	defaultUint32SequenceBits uint32 = 10
 	// minUint32 values.
 	minUint32ProcessBits  = 1
 	minUint32ServerBits   = 1
-	minUint32SequenceBits = 15
+	minUint32SequenceBits = 8
 
+	// minUint32TimestampBits keeps the timestamp from wrapping too soon,
+	// 16 bits of hours last about 7 years.
+	minUint32TimestampBits = 16
+
+	// totalUint32Bits leaves the other 16 bits for the timestamp.
 	totalUint32Bits = defaultUint32ProcessBits + defaultUint32ServerBits + defaultUint32SequenceBits
 
+	// defaultUint32Tick is coarse enough for the 16 timestamp bits to last years.
+	defaultUint32Tick = time.Hour
+
+	// defaultEpoch is 2026-01-01T00:00:00Z in unix seconds.
+	defaultEpoch = 1767225600
+
 	// environment variables.
-	serverIDKey  = "SERVER_ID"
-	processIDKey = "PROCESS_ID"
+	serverIDKey     = "SERVER_ID"
+	processIDKey    = "PROCESS_ID"
+	datacenterIDKey = "DATACENTER_ID"
 )
 
 // Uint32Config is cocurrently-safe stateful configuration
-// for raceless uint32 id generatation.
-type Uint32Config struct {
-	Epoch,
-	CustomEpoch,
-	LastTime,
-	Sequence,
-	ProcessBits,
-	ServerBits,
-	SequenceBits uint32
-	*sync.Mutex
-}
+// for raceless uint32 id generatation, see Config.
+type Uint32Config = Config[uint32]
+
+// Uint32Parts holds the fields an uint32 id is made of.
+type Uint32Parts = Parts[uint32]
 
 // NewUint32Config makes reasonable Uint32Config from the arguments provided,
 //
@@ -44,12 +51,14 @@ type Uint32Config struct {
 // Examples:
 //
 // * Horizontal scaling:
-//   processBits: 5, serverBits: 10, sequenceBits 12
-//   This will support upto 32 processes and 1024 servers.
+//   processBits: 2, serverBits: 6, sequenceBits 8
+//   This will support upto 4 processes and 64 servers.
 //
 // * Vertical scaling:
-//   processBits: 6, serverBits: 1, sequenceBits: 20
+//   processBits: 6, serverBits: 1, sequenceBits: 9
 //   This will support upto 64 processes.
+//
+// Up to 16 bits are used in total, the other 16 bits hold the timestamp in hours.
 func NewUint32Config(serverBits, processBits, sequenceBits uint32) Uint32Config {
 	if processBits < minUint32ProcessBits {
 		processBits = minUint32ProcessBits
@@ -60,7 +69,7 @@ func NewUint32Config(serverBits, processBits, sequenceBits uint32) Uint32Config
 	}
 
 	if sequenceBits < minUint32SequenceBits {
-		sequenceBits = minUint32ServerBits
+		sequenceBits = minUint32SequenceBits
 	}
 
 	if processBits+serverBits+sequenceBits > totalUint32Bits {
@@ -72,32 +81,53 @@ func NewUint32Config(serverBits, processBits, sequenceBits uint32) Uint32Config
 		sequenceBits = totalUint32Bits - processBits - serverBits
 	}
 
-	var (
-		now         = time.Now()
-		epoch       = uint32(now.Unix())
-		customEpoch = uint32(now.Unix()) - epoch
-	)
-
 	return Uint32Config{
-		CustomEpoch:  customEpoch,
-		LastTime:     epoch,
+		Epoch:        defaultEpoch,
+		CustomEpoch:  0,
+		LastTime:     0,
 		Sequence:     0,
 		ProcessBits:  processBits,
 		ServerBits:   serverBits,
 		SequenceBits: sequenceBits,
+		TickDuration: defaultUint32Tick,
 		Mutex:        &sync.Mutex{},
 	}
 }
 
+// NewUint32ConfigWithOptions makes Uint32Config from the exact arguments provided,
+// unlike NewUint32Config no bits are replaced by defaults, instead an error wrapping
+// ErrInvalidLayout is returned for impossible layouts, see Config.Validate.
+//
+// opts such as WithEpoch customize the configuration further.
+func NewUint32ConfigWithOptions(serverBits, processBits, sequenceBits uint32, opts ...Option) (Uint32Config, error) {
+	return newConfig(serverBits, processBits, sequenceBits, nil, opts)
+}
+
 // DefaultUint32Config sets:
-// processBits to 5, which supports upto 32 processes per server
-// serverBits: 10,  which supports upto 1024 servers
-// sequenceBits: 12, which supports upto 4096 ids per time instance.
-var DefaultUint32Config = NewUint32Config(minUint32ServerBits, minUint32ServerBits, defaultUint32SequenceBits)
+// processBits to 2, which supports upto 4 processes per server
+// serverBits: 4,  which supports upto 16 servers
+// sequenceBits: 10, which supports upto 1024 ids per hour
+// leaving 16 bits for the timestamp which last about 7 years, until 2033-06 from the default epoch,
+// afterwards Next returns ErrTimestampOverflow and Uint32 returns zero.
+//
+// It is made by NewUint32Config rather than validated, so that the package still loads past expiry.
+var DefaultUint32Config = NewUint32Config(defaultUint32ServerBits, defaultUint32ProcessBits, defaultUint32SequenceBits)
 
 // Uint32 generates an uint32 id using serverID, processID and config
 // if processID is zero, then the system pid will be used.
+//
+// Zero is returned when no id can be generated, EnvUint32 and Next report the reason instead:
+// the clock moved backwards under RollbackError,
+// the state file of WithStateFile cannot be written,
+// or the timestamp bits are used up, see Expires.
 func Uint32(serverID, processID uint32, c *Uint32Config) uint32 {
+	id, _ := uint32ID(serverID, processID, c)
+
+	return id
+}
+
+// uint32ID is Uint32 reporting the error of c.next.
+func uint32ID(serverID, processID uint32, c *Uint32Config) (uint32, error) {
 	if serverID == 0 {
 		serverID = 1
 	}
@@ -106,24 +136,7 @@ func Uint32(serverID, processID uint32, c *Uint32Config) uint32 {
 		processID = uint32(os.Getpid())
 	}
 
-	c.Lock()
-	defer c.Unlock()
-
-	if c.CustomEpoch <= c.LastTime {
-		c.Sequence++
-		if c.Sequence == (2 << (c.SequenceBits - 1)) {
-			c.Sequence = 0
-			c.LastTime++
-		}
-	} else {
-		c.Sequence = 0
-		c.LastTime = c.CustomEpoch
-	}
-
-	return c.LastTime<<(c.ServerBits+c.ProcessBits+c.SequenceBits) |
-		(serverID&(2<<(c.ServerBits-1)))<<(c.ProcessBits+c.SequenceBits) |
-		(processID & (2 << (c.ProcessBits - 1)) << c.SequenceBits) |
-		c.Sequence
+	return c.next(context.Background(), c.base(serverID, processID), true)
 }
 
 // EnvUint32 generates an uint32 id from envirment variables
@@ -150,5 +163,5 @@ func EnvUint32(c *Uint32Config) (uint32, error) {
 		return 0, fmt.Errorf("parsing processID from env("+processIDKey+") -> %v", err)
 	}
 
-	return Uint32(uint32(serverID), uint32(processID), c), nil
+	return uint32ID(uint32(serverID), uint32(processID), c)
 }
@@ -1,10 +1,15 @@
 package oneid
 
 import (
+	"errors"
 	"log"
+	"math"
 	"os"
 	"sync"
 	"testing"
+	"time"
+
+	"github.com/coderme/oneid/v3/oneidtest"
 )
 
 func cleanEnvVars() {
@@ -88,6 +93,164 @@ func estNewUint32ConfigTotalBitsLength(t *testing.T) {
 	}
 }
 
+// TestNewUint32ConfigWithOptionsEpoch tests NewUint32ConfigWithOptions honors WithEpoch.
+func TestNewUint32ConfigWithOptionsEpoch(t *testing.T) {
+	t.Parallel()
+
+	epoch := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	c, err := NewUint32ConfigWithOptions(1, 1, 8, WithEpoch(epoch))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if c.Epoch != uint32(epoch.Unix()) {
+		t.Error("Epoch is not set, expected:", epoch.Unix(), "found:", c.Epoch)
+	}
+
+	_, err = NewUint32ConfigWithOptions(1, 1, 8, WithEpoch(time.Now().Add(time.Hour)))
+	if !errors.Is(err, ErrEpochInFuture) {
+		t.Error("expected ErrEpochInFuture, found:", err)
+	}
+
+	_, err = NewUint32ConfigWithOptions(1, 1, 8, WithEpoch(time.Unix(-1, 0)))
+	if !errors.Is(err, ErrInvalidEpoch) {
+		t.Error("expected ErrInvalidEpoch, found:", err)
+	}
+}
+
+// TestNewUint32ConfigWithOptionsLayout tests NewUint32ConfigWithOptions rejects impossible layouts.
+func TestNewUint32ConfigWithOptionsLayout(t *testing.T) {
+	t.Parallel()
+
+	data := []struct {
+		serverBits,
+		processBits,
+		sequenceBits uint32
+		isError bool
+	}{
+		{serverBits: 4, processBits: 2, sequenceBits: 10, isError: false},
+		{serverBits: 0, processBits: 2, sequenceBits: 10, isError: true},
+		{serverBits: 4, processBits: 0, sequenceBits: 10, isError: true},
+		{serverBits: 4, processBits: 2, sequenceBits: 0, isError: true},
+		{serverBits: 5, processBits: 10, sequenceBits: 20, isError: true},
+		{serverBits: 4, processBits: 2, sequenceBits: 11, isError: true},
+		{serverBits: math.MaxUint32, processBits: 2, sequenceBits: 3, isError: true},
+	}
+
+	for _, v := range data {
+		c, err := NewUint32ConfigWithOptions(v.serverBits, v.processBits, v.sequenceBits)
+		if v.isError && !errors.Is(err, ErrInvalidLayout) {
+			t.Error("expected ErrInvalidLayout for", v.serverBits, v.processBits, v.sequenceBits, "found:", err)
+		}
+
+		if !v.isError && err != nil {
+			t.Error("expected no error for", v.serverBits, v.processBits, v.sequenceBits, "found:", err)
+		}
+
+		if !v.isError && c.ServerBits != v.serverBits {
+			t.Error("ServerBits is replaced, expected:", v.serverBits, "found:", c.ServerBits)
+		}
+	}
+}
+
+// TestUint32Expires tests Uint32 returns zero rather than wrapping ids around once the clock passes Expires.
+func TestUint32Expires(t *testing.T) {
+	t.Parallel()
+
+	clk := oneidtest.NewClock(time.Now())
+
+	c, err := NewUint32ConfigWithOptions(4, 2, 10, WithClock(clk))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	clk.Set(c.Expires().Add(-time.Hour))
+
+	if id := Uint32(1, 1, &c); id == 0 {
+		t.Error("expected an id in the last tick")
+	}
+
+	clk.Set(c.Expires().Add(time.Hour))
+
+	if id, err := uint32ID(1, 1, &c); !errors.Is(err, ErrTimestampOverflow) {
+		t.Error("expected ErrTimestampOverflow, found:", id, err)
+	}
+
+	if id := Uint32(1, 1, &c); id != 0 {
+		t.Error("expected a zero id, found:", id)
+	}
+}
+
+// TestDefaultUint32ConfigExpired tests the default config is built the same past Expires,
+// expiry is only reported by the calls generating ids.
+func TestDefaultUint32ConfigExpired(t *testing.T) {
+	t.Parallel()
+
+	c := NewUint32Config(defaultUint32ServerBits, defaultUint32ProcessBits, defaultUint32SequenceBits)
+
+	if c.ServerBits != DefaultUint32Config.ServerBits || c.ProcessBits != DefaultUint32Config.ProcessBits ||
+		c.SequenceBits != DefaultUint32Config.SequenceBits || !c.Expires().Equal(DefaultUint32Config.Expires()) {
+		t.Fatal("config", c, "is not the default", DefaultUint32Config)
+	}
+
+	c.Clock = oneidtest.NewClock(c.Expires().Add(time.Hour))
+
+	if _, err := c.Next(1, 1); !errors.Is(err, ErrTimestampOverflow) {
+		t.Error("expected ErrTimestampOverflow, found:", err)
+	}
+
+	if id := Uint32(1, 1, &c); id != 0 {
+		t.Error("expected a zero id, found:", id)
+	}
+}
+
+// TestUint32DistinctServerIDs tests Uint32 keeps every serverID of the layout apart.
+func TestUint32DistinctServerIDs(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint32ConfigWithOptions(3, 3, 4)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for s := uint32(1); s <= mask(c.ServerBits); s++ {
+		if parts := c.Decode(Uint32(s, 1, &c)); parts.ServerID != s || parts.ProcessID != 1 {
+			t.Error("decoded serverID:", parts.ServerID, "processID:", parts.ProcessID, "expected:", s, 1)
+		}
+	}
+}
+
+// TestUint32ConfigLifetime tests Lifetime of the 32-bit layouts and rejecting expired ones.
+func TestUint32ConfigLifetime(t *testing.T) {
+	t.Parallel()
+
+	if l := DefaultUint32Config.Lifetime(); l != 1<<16*time.Hour {
+		t.Error("DefaultUint32Config lifetime is", l, "expected:", 1<<16*time.Hour)
+	}
+
+	minutes, err := NewUint32ConfigWithOptions(4, 2, 10, WithTick(time.Minute), WithEpoch(time.Now().Add(-time.Hour)))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if l := minutes.Lifetime(); l != 1<<16*time.Minute {
+		t.Error("lifetime of minute ticks is", l, "expected:", 1<<16*time.Minute)
+	}
+
+	_, err = NewUint32ConfigWithOptions(8, 4, 4, WithEpoch(time.Now().Add(-70_000*time.Hour)))
+	if !errors.Is(err, ErrInvalidLayout) {
+		t.Error("expected ErrInvalidLayout for an expired layout, found:", err)
+	}
+
+	c := NewUint32Config(defaultUint32ServerBits, defaultUint32ProcessBits, defaultUint32SequenceBits)
+
+	parts := c.Decode(Uint32(1, 1, &c))
+	if now := time.Now(); parts.Time.After(now) || parts.Time.Before(now.Add(-time.Hour)) {
+		t.Error("decoded time", parts.Time, "is not within the current hour")
+	}
+}
+
 // TestNewCustomInt6ZeroId tests CustomUint32 for any zero id.
 func TestNewCustomUint32ZeroId(t *testing.T) {
 	t.Parallel()
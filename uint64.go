@@ -1,6 +1,7 @@
 package oneid
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strconv"
@@ -11,28 +12,29 @@ import (
 const (
 	defaultUint64ProcessBits  uint64 = 5
 	defaultUint64ServerBits   uint64 = 10
-	defaultUint64SequenceBits uint64 = 24
+	defaultUint64SequenceBits uint64 = 17
 
 	// min values.
 	minUint64ProcessBits  uint64 = 1
 	minUint64ServerBits   uint64 = 1
-	minUint64SequenceBits uint64 = 24
+	minUint64SequenceBits uint64 = 12
 
-	totalUint64Bits uint64 = defaultUint64ProcessBits + defaultUint64ServerBits + defaultUint64SequenceBits
+	// minUint64TimestampBits supports upto 136 years of seconds.
+	minUint64TimestampBits uint64 = 32
+
+	// totalUint64Bits leaves the other 32 bits for the timestamp.
+	totalUint64Bits uint64 = 64 - minUint64TimestampBits
+
+	// defaultUint64Tick is the timestamp resolution unless a config sets its own.
+	defaultUint64Tick = time.Second
 )
 
 // Uint64Config is cocurrently-safe stateful configuration
-// for raceless uint64 id generatation.
-type Uint64Config struct {
-	Epoch,
-	CustomEpoch,
-	LastTime,
-	Sequence,
-	ProcessBits,
-	ServerBits,
-	SequenceBits uint64
-	*sync.Mutex
-}
+// for raceless uint64 id generatation, see Config.
+type Uint64Config = Config[uint64]
+
+// Uint64Parts holds the fields an uint64 id is made of.
+type Uint64Parts = Parts[uint64]
 
 // NewUint64Config makes reasonable Unt64Config from the arguments passed,
 //
@@ -41,12 +43,15 @@ type Uint64Config struct {
 // Examples:
 //
 // * Horizontal scaling:
-//   processBits: 5, serverBits: 10, sequenceBits 24
+//   processBits: 5, serverBits: 10, sequenceBits 17
 //   This will support upto 32 processes and 1024 servers.
 //
 // * Vertical scaling:
 //   processBits: 6, serverBits: 1, sequenceBits: 20
 //   This will support upto 64 processes.
+//
+// Up to 32 bits are used in total, the other 32 bits hold the timestamp in seconds,
+// sequenceBits shrinks to fit when serverBits and processBits leave room for at least 12 of them.
 func NewUint64Config(serverBits, processBits, sequenceBits uint64) Uint64Config {
 	if processBits < minUint64ProcessBits {
 		processBits = minUint64ProcessBits
@@ -60,63 +65,64 @@ func NewUint64Config(serverBits, processBits, sequenceBits uint64) Uint64Config
 		sequenceBits = minUint64SequenceBits
 	}
 
-	if processBits+serverBits+sequenceBits > totalUint64Bits {
+	if processBits+serverBits+minUint64SequenceBits > totalUint64Bits {
 		processBits = defaultUint64ProcessBits
 		serverBits = defaultUint64ServerBits
 		sequenceBits = defaultUint64SequenceBits
-	} else if processBits+serverBits+sequenceBits < totalUint64Bits {
-		// max out bits for sequenceBits
+	} else if processBits+serverBits+sequenceBits != totalUint64Bits {
+		// max out bits for sequenceBits, or shrink them to keep the timestamp bits.
 		sequenceBits = totalUint64Bits - processBits - serverBits
 	}
 
-	var (
-		now         = time.Now()
-		epoch       = uint64(now.Unix())
-		customEpoch = uint64(now.Unix()) - epoch
-	)
-
 	return Uint64Config{
-		CustomEpoch:  customEpoch,
-		LastTime:     epoch,
+		Epoch:        defaultEpoch,
+		CustomEpoch:  0,
+		LastTime:     0,
 		Sequence:     0,
 		ProcessBits:  processBits,
 		ServerBits:   serverBits,
 		SequenceBits: sequenceBits,
+		TickDuration: defaultUint64Tick,
 		Mutex:        &sync.Mutex{},
 	}
 }
 
+// NewUint64ConfigWithOptions makes Uint64Config from the exact arguments provided,
+// unlike NewUint64Config no bits are replaced by defaults, instead an error wrapping
+// ErrInvalidLayout is returned for impossible layouts, see Config.Validate.
+//
+// opts such as WithEpoch customize the configuration further.
+func NewUint64ConfigWithOptions(serverBits, processBits, sequenceBits uint64, opts ...Option) (Uint64Config, error) {
+	return newConfig(serverBits, processBits, sequenceBits, nil, opts)
+}
+
 // DefaultUint64Config sets:
-// processBits to 5, which supports upto 32 processes per server
-// serverBits: 10,  which supports upto 1024 servers
-// sequenceBits: 24, which supports upto 16,777,216 ids per time instance.
+// processBits to 1, which supports upto 2 processes per server
+// serverBits: 1,  which supports upto 2 servers
+// sequenceBits: 30, which supports upto 1,073,741,824 ids per time instance
+// leaving 32 bits for the timestamp, see NewUint64Config.
 var DefaultUint64Config = NewUint64Config(minUint64ServerBits, minUint64ProcessBits, defaultUint64SequenceBits)
 
 // Uint64 generates uint64 id using  using serverID, processID and config
 // if processID is zero, then the system pid will be used.
+//
+// Zero is returned when no id can be generated, EnvUint64 and Next report the reason instead:
+// the clock moved backwards under RollbackError,
+// the state file of WithStateFile cannot be written,
+// or the timestamp bits are used up, see Expires.
 func Uint64(serverID, processID uint64, c *Uint64Config) uint64 {
+	id, _ := uint64ID(serverID, processID, c)
+
+	return id
+}
+
+// uint64ID is Uint64 reporting the error of c.next.
+func uint64ID(serverID, processID uint64, c *Uint64Config) (uint64, error) {
 	if processID == 0 {
 		processID = uint64(os.Getpid())
 	}
 
-	c.Lock()
-	defer c.Unlock()
-
-	if c.CustomEpoch <= c.LastTime {
-		c.Sequence++
-		if c.Sequence == (2 << (c.SequenceBits - 1)) {
-			c.Sequence = 0
-			c.LastTime++
-		}
-	} else {
-		c.Sequence = 0
-		c.LastTime = c.CustomEpoch
-	}
-
-	return c.LastTime<<(c.ServerBits+c.ProcessBits+c.SequenceBits) |
-		(serverID&(2<<(c.ServerBits-1)))<<(c.ProcessBits+c.SequenceBits) |
-		(processID & (2 << (c.ProcessBits - 1)) << c.SequenceBits) |
-		c.Sequence
+	return c.next(context.Background(), c.base(serverID, processID), true)
 }
 
 // EnvUnt64 generates an uint64 id from envirment variables
@@ -139,5 +145,5 @@ func EnvUint64(c *Uint64Config) (uint64, error) {
 		return 0, fmt.Errorf("parsing processID from env("+processIDKey+") -> %v", err)
 	}
 
-	return Uint64(serverID, processID, c), nil
+	return uint64ID(serverID, processID, c)
 }
@@ -1,10 +1,15 @@
 package oneid
 
 import (
+	"errors"
 	"log"
+	"math"
 	"os"
 	"sync"
 	"testing"
+	"time"
+
+	"github.com/coderme/oneid/v3/oneidtest"
 )
 
 // TestNewUint64ConfigMinValues tests NewUint64Config for using minimal values configuration.
@@ -51,6 +56,36 @@ func TestNewUint64ConfigSequanceBitsIsMax(t *testing.T) {
 	}
 }
 
+// TestNewUint64ConfigTimestampBits tests NewUint64Config keeps 32 bits for the timestamp,
+// shrinking sequenceBits rather than serverBits and processBits.
+func TestNewUint64ConfigTimestampBits(t *testing.T) {
+	t.Parallel()
+
+	values := [4][6]uint64{
+		{0, 0, 0, 1, 1, 30},
+		{1, 1, 37, 1, 1, 30},
+		{14, 5, 20, 14, 5, 13},
+		{20, 20, 20, defaultUint64ServerBits, defaultUint64ProcessBits, defaultUint64SequenceBits},
+	}
+
+	for _, v := range values {
+		c := NewUint64Config(v[0], v[1], v[2])
+
+		if c.ServerBits != v[3] || c.ProcessBits != v[4] || c.SequenceBits != v[5] {
+			t.Error("expected bits", v[3:], "for", v[:3], "found:", c.ServerBits, c.ProcessBits, c.SequenceBits)
+		}
+
+		if l := c.Lifetime(); l != 1<<minUint64TimestampBits*time.Second {
+			t.Error("lifetime of", v[:3], "is", l, "expected:", 1<<minUint64TimestampBits*time.Second)
+		}
+	}
+
+	// DefaultUint64Config keeps the layout of NewUint64Config(1, 1, ...).
+	if c := &DefaultUint64Config; c.ServerBits != 1 || c.ProcessBits != 1 || c.SequenceBits != 30 {
+		t.Error("DefaultUint64Config bits are", c.ServerBits, c.ProcessBits, c.SequenceBits, "expected: 1 1 30")
+	}
+}
+
 // TestNewUint64ConfigTotalBitsLength tests NewUint64Config total bits length.
 func estNewUint64ConfigTotalBitsLength(t *testing.T) {
 	t.Parallel()
@@ -72,6 +107,321 @@ func estNewUint64ConfigTotalBitsLength(t *testing.T) {
 	}
 }
 
+// TestNewUint64ConfigWithOptionsEpoch tests NewUint64ConfigWithOptions honors WithEpoch.
+func TestNewUint64ConfigWithOptionsEpoch(t *testing.T) {
+	t.Parallel()
+
+	epoch := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	c, err := NewUint64ConfigWithOptions(1, 1, minUint64SequenceBits, WithEpoch(epoch))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if c.Epoch != uint64(epoch.Unix()) {
+		t.Error("Epoch is not set, expected:", epoch.Unix(), "found:", c.Epoch)
+	}
+
+	_, err = NewUint64ConfigWithOptions(1, 1, minUint64SequenceBits, WithEpoch(time.Now().Add(time.Hour)))
+	if !errors.Is(err, ErrEpochInFuture) {
+		t.Error("expected ErrEpochInFuture, found:", err)
+	}
+
+	_, err = NewUint64ConfigWithOptions(1, 1, minUint64SequenceBits, WithEpoch(time.Unix(-1, 0)))
+	if !errors.Is(err, ErrInvalidEpoch) {
+		t.Error("expected ErrInvalidEpoch, found:", err)
+	}
+}
+
+// TestNewUint64ConfigWithOptionsLayout tests NewUint64ConfigWithOptions rejects impossible layouts.
+func TestNewUint64ConfigWithOptionsLayout(t *testing.T) {
+	t.Parallel()
+
+	data := []struct {
+		serverBits,
+		processBits,
+		sequenceBits uint64
+		isError bool
+	}{
+		{serverBits: 10, processBits: 5, sequenceBits: 17, isError: false},
+		{serverBits: 0, processBits: 5, sequenceBits: 17, isError: true},
+		{serverBits: 10, processBits: 0, sequenceBits: 17, isError: true},
+		{serverBits: 10, processBits: 5, sequenceBits: 0, isError: true},
+		{serverBits: 10, processBits: 5, sequenceBits: 24, isError: true},
+		{serverBits: 30, processBits: 30, sequenceBits: 30, isError: true},
+		{serverBits: math.MaxUint64, processBits: 5, sequenceBits: 3, isError: true},
+	}
+
+	for _, v := range data {
+		c, err := NewUint64ConfigWithOptions(v.serverBits, v.processBits, v.sequenceBits)
+		if v.isError && !errors.Is(err, ErrInvalidLayout) {
+			t.Error("expected ErrInvalidLayout for", v.serverBits, v.processBits, v.sequenceBits, "found:", err)
+		}
+
+		if !v.isError && err != nil {
+			t.Error("expected no error for", v.serverBits, v.processBits, v.sequenceBits, "found:", err)
+		}
+
+		if !v.isError && c.ServerBits != v.serverBits {
+			t.Error("ServerBits is replaced, expected:", v.serverBits, "found:", c.ServerBits)
+		}
+	}
+}
+
+// TestUint64RollbackBorrowedTicks tests borrowing the following ticks under a frozen clock
+// is not mistaken for the clock moving backwards.
+func TestUint64RollbackBorrowedTicks(t *testing.T) {
+	t.Parallel()
+
+	clk := oneidtest.NewClock(time.Now())
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 4, WithRollbackPolicy(RollbackError), WithClock(clk))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for i := 0; i < 10<<c.SequenceBits; i++ {
+		if _, err := uint64ID(1, 1, &c); err != nil {
+			t.Fatal("unexpected error at id", i, "error:", err)
+		}
+	}
+
+	if d := c.Drift(); d != 9*time.Second {
+		t.Error("expected a drift of 9s, found:", d)
+	}
+}
+
+// TestUint64DistinctServerIDs tests Uint64 keeps every serverID of the layout apart.
+func TestUint64DistinctServerIDs(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(3, 3, 4)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for s := uint64(1); s <= mask(c.ServerBits); s++ {
+		if parts := c.Decode(Uint64(s, 1, &c)); parts.ServerID != s || parts.ProcessID != 1 {
+			t.Error("decoded serverID:", parts.ServerID, "processID:", parts.ProcessID, "expected:", s, 1)
+		}
+	}
+}
+
+// TestUint64ConfigTick tests WithTick drives both Next and Decode.
+func TestUint64ConfigTick(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewUint64ConfigWithOptions(10, 5, 12, WithTick(-time.Millisecond)); err == nil {
+		t.Error("expected an error for a negative tick")
+	}
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 12, WithTick(10*time.Millisecond))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if c.TickDuration != 10*time.Millisecond {
+		t.Error("TickDuration is not set, found:", c.TickDuration)
+	}
+
+	before := time.Now().Add(-10 * time.Millisecond)
+
+	id, err := c.Next(1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if parts := c.Decode(id); parts.Time.Before(before) || parts.Time.After(time.Now()) {
+		t.Error("decoded time", parts.Time, "is not within 10ms of", before)
+	}
+
+	if l := c.Lifetime(); l != 1<<37*10*time.Millisecond {
+		t.Error("lifetime is", l, "expected:", 1<<37*10*time.Millisecond)
+	}
+}
+
+// TestUint64MaxDrift tests Uint64 waits for the clock instead of borrowing ticks beyond MaxDrift.
+func TestUint64MaxDrift(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewUint64ConfigWithOptions(10, 5, 4, WithMaxDrift(-time.Second)); err == nil {
+		t.Error("expected an error for a negative max drift")
+	}
+
+	const (
+		tick     = 10 * time.Millisecond
+		maxDrift = 3 * tick
+	)
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 4, WithTick(tick), WithMaxDrift(maxDrift))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if d := c.Drift(); d != 0 {
+		t.Error("expected no drift before any id, found:", d)
+	}
+
+	var prev uint64
+
+	// 200 ids need more than 12 ticks of 16 sequence numbers.
+	for i := 0; i < 200; i++ {
+		id := Uint64(1, 1, &c)
+		if id <= prev {
+			t.Fatal("id", id, "is not after", prev)
+		}
+
+		prev = id
+
+		if d := c.Drift(); d > maxDrift+tick {
+			t.Fatal("drift", d, "exceeds", maxDrift)
+		}
+	}
+}
+
+// TestUint64ConfigClock tests ticks, rollback, drift and overflow against a manual clock.
+func TestUint64ConfigClock(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now().Truncate(time.Second)
+	clk := oneidtest.NewClock(start)
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 4, WithEpoch(start.Add(-time.Hour)), WithClock(clk),
+		WithRollbackPolicy(RollbackError))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for i := 0; i < 1<<c.SequenceBits; i++ {
+		if _, err := c.Next(1, 1); err != nil {
+			t.Fatal("unexpected error at sequence", i, "error:", err)
+		}
+	}
+
+	if _, err := c.Next(1, 1); !errors.Is(err, ErrSequenceExhausted) {
+		t.Error("expected ErrSequenceExhausted, found:", err)
+	}
+
+	clk.Advance(time.Second)
+
+	id, err := c.Next(1, 1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if parts := c.Decode(id); !parts.Time.Equal(start.Add(time.Second)) || parts.Sequence != 0 {
+		t.Error("expected the first id of", start.Add(time.Second), "found:", parts)
+	}
+
+	clk.Set(start.Add(-time.Second))
+
+	if _, err := c.Next(1, 1); !errors.Is(err, ErrClockMovedBackwards) {
+		t.Error("expected ErrClockMovedBackwards, found:", err)
+	}
+
+	if d := c.Drift(); d != 2*time.Second {
+		t.Error("expected a drift of 2s, found:", d)
+	}
+
+	c.RollbackPolicy = RollbackBlock
+	done := make(chan error)
+
+	go func() {
+		_, err := c.Next(1, 1)
+		done <- err
+	}()
+
+	for clk.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	clk.Advance(2 * time.Second)
+
+	if err := <-done; err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	// 32 bits of seconds wrap around in about 136 years.
+	wrapping, err := NewUint64ConfigWithOptions(10, 5, 17, WithEpoch(start.Add(-time.Hour)), WithClock(clk))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	clk.Set(wrapping.Expires())
+
+	if _, err := wrapping.Next(1, 1); !errors.Is(err, ErrTimestampOverflow) {
+		t.Error("expected ErrTimestampOverflow, found:", err)
+	}
+}
+
+// TestUint64Monotonic tests Uint64 stays strictly increasing while borrowing ticks under contention,
+// and reports the end of the timestamp bits instead of wrapping around.
+func TestUint64Monotonic(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 4, WithTick(time.Millisecond))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testMonotonic(t, func() (uint64, error) { return Uint64(1, 1, &c), nil })
+
+	// exhaust the last tick the timestamp bits hold.
+	c.LastTime = c.timestampBitsMask()
+	c.Sequence = 1<<c.SequenceBits - 1
+
+	if _, err := uint64ID(1, 1, &c); !errors.Is(err, ErrTimestampOverflow) {
+		t.Error("expected ErrTimestampOverflow, found:", err)
+	}
+}
+
+// TestUint64Expires tests Uint64 and HLCGenerator.Next report ErrTimestampOverflow
+// rather than wrapping ids around once the clock passes Expires.
+func TestUint64Expires(t *testing.T) {
+	t.Parallel()
+
+	clk := oneidtest.NewClock(time.Now())
+
+	c, err := NewUint64ConfigWithOptions(10, 5, 17, WithClock(clk))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	hlc, err := NewHLCGenerator(&c, 2, 2)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	clk.Set(c.Expires().Add(-time.Second))
+
+	last, err := uint64ID(1, 1, &c)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if parts := c.Decode(last); !parts.Time.Equal(c.Expires().Add(-time.Second)) {
+		t.Error("expected the last id of", c.Expires().Add(-time.Second), "found:", parts)
+	}
+
+	clk.Set(c.Expires().Add(time.Second))
+
+	if id, err := uint64ID(1, 1, &c); !errors.Is(err, ErrTimestampOverflow) {
+		t.Error("expected ErrTimestampOverflow, found:", id, err)
+	}
+
+	if id := Uint64(1, 1, &c); id != 0 {
+		t.Error("expected a zero id after", last, "found:", id)
+	}
+
+	if id, err := hlc.Next(); !errors.Is(err, ErrTimestampOverflow) {
+		t.Error("expected ErrTimestampOverflow from HLCGenerator, found:", id, err)
+	}
+
+	if id, err := c.Next(1, 1); !errors.Is(err, ErrTimestampOverflow) {
+		t.Error("expected ErrTimestampOverflow from Next, found:", id, err)
+	}
+}
+
 // TestNewCustomUint6ZeroId tests CustomUint64 for any zero id.
 func TestNewCustomUint64ZeroId(t *testing.T) {
 	t.Parallel()
@@ -142,6 +492,22 @@ func TestNewCustomUint64NonDuplicateIdMultipleThreads(t *testing.T) {
 	}
 }
 
+// TestUint64WallClockTimestamp tests Uint64() embeds the seconds elapsed since Epoch.
+func TestUint64WallClockTimestamp(t *testing.T) {
+	t.Parallel()
+
+	c := NewUint64Config(defaultUint64ServerBits, defaultUint64ProcessBits, defaultUint64SequenceBits)
+
+	before := uint64(time.Now().Unix()) - c.Epoch
+	id := Uint64(1, 1, &c)
+	after := uint64(time.Now().Unix()) - c.Epoch
+
+	ts := id >> (c.ServerBits + c.ProcessBits + c.SequenceBits)
+	if ts < before || ts > after {
+		t.Error("timestamp", ts, "is not within", before, "and", after)
+	}
+}
+
 // TestUint64ZeroServerID calls Uint64() with server id = 0.
 // checks for returning a non zero id
 func TestUint64ZeroServerID(t *testing.T) {
@@ -330,9 +696,29 @@ func TestEnvUint64(t *testing.T) {
 	}
 }
 
+// mustUint64Config panics if err is not nil, so benchmarks build their config in one line.
+func mustUint64Config(c Uint64Config, err error) Uint64Config {
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
 // BenchmarkUint64 benchmarks a Uint64(1).
 func BenchmarkUint64(b *testing.B) {
 	for c := 0; c < b.N; c++ {
 		_ = Uint64(1, 0, &DefaultUint64Config)
 	}
 }
+
+// BenchmarkUint64Parallel benchmarks Uint64 under contention, see BenchmarkAtomicGeneratorParallel.
+func BenchmarkUint64Parallel(b *testing.B) {
+	c := mustUint64Config(NewUint64ConfigWithOptions(1, 1, 30))
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = Uint64(1, 1, &c)
+		}
+	})
+}